@@ -0,0 +1,81 @@
+package draw
+
+import (
+	"context"
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/cep21/benchdraw/internal"
+	"github.com/pkg/errors"
+)
+
+// AutoChart is a chart Auto picks
+type AutoChart struct {
+	// Name is unique to each chart, like BenchmarkDecode_ns_op, and safe to use as a file name
+	Name    string
+	Options Options
+}
+
+// Auto reads benchmark output from in and picks the charts to draw of it: one for each top level benchmark name that
+// matches opts.Filter and each of its units, or each of opts.Y if set.  Each chart's x is the numeric key with the
+// most values, or else the key with the most values, grouped by every other key that varies.  The Options of each chart
+// are opts with Filter, X, Group, Y and Plot set, and Title if opts has none.
+func Auto(ctx context.Context, in io.Reader, opts Options) ([]AutoChart, error) {
+	p, err := opts.parse()
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid options")
+	}
+	d := newDrawer(opts)
+	var units []string
+	if y := filterEmpty(opts.Y); len(y) != 0 {
+		units = y
+	}
+	filtered, err := d.read(ctx, in, p, units)
+	if err != nil {
+		return nil, err
+	}
+	if units == nil {
+		units = filtered.AllUnits().Order
+	}
+	var unitSet internal.OrderedStringSet
+	for _, u := range units {
+		unitSet.Add(u)
+	}
+	var ret []AutoChart
+	for _, c := range internal.AutoCharts(filtered) {
+		if !unitSet.Contains(c.Unit) {
+			continue
+		}
+		chart := opts
+		chart.Filter = c.Benchmark
+		if opts.Filter != "" {
+			chart.Filter = opts.Filter + "/" + c.Benchmark
+		}
+		if chart.Title == "" {
+			chart.Title = c.Benchmark
+		}
+		chart.X = c.X
+		chart.Group = c.Group
+		chart.Y = []string{c.Unit}
+		chart.Plot = "bar"
+		if c.Plot == internal.PlotTypeLine {
+			chart.Plot = "line"
+		}
+		ret = append(ret, AutoChart{
+			Name:    fileSafe(c.Benchmark + "_" + c.Unit),
+			Options: chart,
+		})
+	}
+	return ret, nil
+}
+
+// fileSafe replaces everything but letters, numbers, _, - and . with _
+func fileSafe(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.' {
+			return r
+		}
+		return '_'
+	}, s)
+}
@@ -0,0 +1,73 @@
+package draw
+
+import (
+	"context"
+	"io"
+
+	"github.com/cep21/benchdraw/internal"
+	"github.com/pkg/errors"
+)
+
+// CheckResult is how one unit of a benchmark changed from the baseline, and if that is a regression
+type CheckResult = internal.CheckResult
+
+// CheckReport is how every benchmark in both the input and the baseline changed
+type CheckReport struct {
+	// Results are each benchmark for each unit of Options.Y, in that order
+	Results []CheckResult
+}
+
+// Regressed is how many results regressed more than their threshold
+func (r CheckReport) Regressed() int {
+	ret := 0
+	for _, res := range r.Results {
+		if res.Regressed {
+			ret++
+		}
+	}
+	return ret
+}
+
+// Write writes a line for each result, regressions first, followed by how many regressed
+func (r CheckReport) Write(out io.Writer) error {
+	return internal.WriteCheckReport(out, r.Results)
+}
+
+// defaultThreshold is the percent a benchmark can regress by, unless Options.Threshold sets another
+const defaultThreshold = 5
+
+// Check reads benchmark output from in and compares each benchmark that matches opts.Filter to the same benchmark of
+// opts.Baseline, for each unit of opts.Y.  A benchmark regressed if it got worse by more than its threshold of
+// opts.Threshold.
+func Check(ctx context.Context, in io.Reader, opts Options) (CheckReport, error) {
+	if opts.Baseline == nil {
+		return CheckReport{}, errors.New("check needs a baseline to compare against")
+	}
+	p, err := opts.parse()
+	if err != nil {
+		return CheckReport{}, errors.Wrap(err, "invalid options")
+	}
+	thresholds, err := internal.ToThresholds(opts.Threshold, defaultThreshold)
+	if err != nil {
+		return CheckReport{}, errors.Wrapf(err, "unable to understand threshold %s", opts.Threshold)
+	}
+	d := newDrawer(opts)
+	candidate, err := d.read(ctx, in, p, p.ys)
+	if err != nil {
+		return CheckReport{}, err
+	}
+	baseline, err := d.read(ctx, p.baseline, p, p.ys)
+	if err != nil {
+		return CheckReport{}, errors.Wrap(err, "unable to read baseline")
+	}
+	var ret CheckReport
+	for _, unit := range p.ys {
+		deltas := internal.ComputeDeltas(baseline, candidate, unit, p.agg)
+		d.log.Log(3, "deltas of %s: %v", unit, deltas)
+		ret.Results = append(ret.Results, internal.CheckDeltas(deltas, unit, thresholds)...)
+	}
+	if len(ret.Results) == 0 {
+		return CheckReport{}, errors.New("no benchmarks are in both the baseline and the input")
+	}
+	return ret, nil
+}
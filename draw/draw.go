@@ -0,0 +1,445 @@
+// Package draw turns Go benchmark output into charts.  It is what the benchdraw command runs, for programs that want
+// to draw charts without running the command.
+package draw
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"log"
+	"strings"
+
+	"github.com/cep21/benchdraw/internal"
+	"github.com/pkg/errors"
+)
+
+// Options pick which benchmarks to draw and how.  Each field is the same as the benchdraw flag of the same name,
+// and the zero value of a field is that flag's default.
+type Options struct {
+	// Filter picks which benchmarks to draw.  See the README for the syntax.
+	Filter string
+	// Title of the chart.  If empty, uses Filter.
+	Title string
+	// Group are the benchmark keys that pick which line each benchmark is part of.  If empty, groups by every key
+	// but X.
+	Group []string
+	// Plot is one of bar, line, box or delta
+	Plot string
+	// Agg combines the values at each x.  One of mean, median, min, max, sum, geomean, count or pN.
+	Agg string
+	// ErrorBars is one of none, stddev, stderr, ci95 or minmax
+	ErrorBars string
+	// Normalize, as key=value, draws each group relative to the group with that key and value
+	Normalize string
+	// XScale is one of auto, nominal or numeric
+	XScale string
+	// XSort and GroupSort are one of input, alpha, natural, numeric or value
+	XSort     string
+	GroupSort string
+	LogX      bool
+	LogY      bool
+	// X is the benchmark key on the x axis
+	X string
+	// Y are the units drawn, each as its own panel.  If empty, uses the names of YExpr, or ns/op.
+	Y []string
+	// YExpr are units computed from other units, like `ops/s=1e9 / ns/op`
+	YExpr []string
+	// Baseline is the benchmark output input is compared to.  Required by delta plots and Check.
+	Baseline io.Reader
+	// Threshold is how many percent each benchmark can regress by in Check.  A comma separated list of a default and
+	// name=percent for benchmarks whose name starts with name, like 5,BenchmarkDecode=10.  If empty, uses 5.
+	Threshold string
+	// Format is the format of the chart.  One of Formats().  If empty, uses svg.
+	Format string
+	// Width, Height and DPI are the size of the chart.  Zero picks a size from the number of values drawn.
+	Width  float64
+	Height float64
+	DPI    int
+	// Logger, if not nil, logs how the chart is drawn at Verbosity
+	Logger    *log.Logger
+	Verbosity int
+}
+
+// Chart is a chart drawn from benchmark output, ready to be rendered in its format
+type Chart struct {
+	chart    internal.Chart
+	renderer internal.Renderer
+	log      internal.Logger
+}
+
+// Title is the title of the chart
+func (c Chart) Title() string {
+	return c.chart.Title
+}
+
+// Format is the format the chart renders as
+func (c Chart) Format() string {
+	return c.chart.Format
+}
+
+// Units are the y units of the chart's values, in the order they are drawn.  Pictures may draw them scaled to a
+// readable unit.
+func (c Chart) Units() []string {
+	if c.chart.Type == internal.PlotTypeDelta {
+		return []string{c.chart.Unit}
+	}
+	ret := make([]string, 0, len(c.chart.Panels))
+	for _, p := range c.chart.Panels {
+		ret = append(ret, p.Y)
+	}
+	return ret
+}
+
+// Render writes the chart to out in its format
+func (c Chart) Render(out io.Writer) error {
+	return c.renderer.Render(c.log, out, c.chart)
+}
+
+// Data is what the chart's Renderer draws
+func (c Chart) Data() ChartData {
+	return c.chart
+}
+
+var renderers = &internal.Registry{}
+
+// Register adds format to Formats(), drawn by renderer.  If format is already a format, renderer replaces how it is
+// drawn.  Programs usually register their formats from an init function.
+func Register(format string, renderer Renderer) {
+	renderers.Register(format, renderer)
+}
+
+// Formats returns every format a chart can be rendered as
+func Formats() []string {
+	return renderers.Formats()
+}
+
+// ToFormat checks format is one of Formats().  An empty format is inferred from the extension of the output file
+// name, and is svg if that is not a known format either.
+func ToFormat(format string, output string) (string, error) {
+	return renderers.ToFormat(format, output)
+}
+
+// Draw reads benchmark output from in and draws a chart of it.  It is safe to call from many goroutines at once.
+func Draw(ctx context.Context, in io.Reader, opts Options) (Chart, error) {
+	d := newDrawer(opts)
+	p, err := opts.parse()
+	if err != nil {
+		return Chart{}, errors.Wrap(err, "invalid options")
+	}
+	d.log.Log(2, "parsed options: %s", p)
+	return d.draw(ctx, in, p)
+}
+
+type parsedOptions struct {
+	title     string
+	filters   []internal.FilterPair
+	group     []string
+	plot      internal.PlotType
+	agg       internal.Aggregation
+	count     bool
+	errorBars internal.ErrorBars
+	// normalizeKey and normalizeValue pick the group every other group is drawn relative to
+	normalizeKey   string
+	normalizeValue string
+	xscale         internal.XScale
+	xsort          internal.SortOrder
+	groupsort      internal.SortOrder
+	scale          internal.AxisScale
+	x              string
+	// ys is every unit of y, each drawn as its own panel
+	ys []string
+	// exprs compute new units from the units of each benchmark
+	exprs    []*internal.Expression
+	baseline io.Reader
+	// format is the name renderer is registered as
+	format   string
+	style    internal.ChartStyle
+	renderer internal.Renderer
+}
+
+func (p *parsedOptions) String() string {
+	return "[title=" + p.title + " x=" + p.x + " y=" + strings.Join(p.ys, ",") + "]"
+}
+
+func filterEmpty(s []string) []string {
+	ret := make([]string, 0, len(s))
+	for _, i := range s {
+		if len(i) > 0 {
+			ret = append(ret, i)
+		}
+	}
+	return ret
+}
+
+func (o Options) parse() (*parsedOptions, error) {
+	ret := parsedOptions{
+		title:    o.Title,
+		group:    filterEmpty(o.Group),
+		x:        o.X,
+		baseline: o.Baseline,
+		scale: internal.AxisScale{
+			LogX: o.LogX,
+			LogY: o.LogY,
+		},
+	}
+	if ret.title == "" {
+		ret.title = o.Filter
+	}
+	filters, err := internal.ToFilterPairs(o.Filter)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to understand filter %s", o.Filter)
+	}
+	ret.filters = filters
+	for _, s := range filterEmpty(o.YExpr) {
+		e, err := internal.ToExpression(s)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to understand y expression %s", s)
+		}
+		ret.exprs = append(ret.exprs, e)
+	}
+	ret.ys = filterEmpty(o.Y)
+	// Without Y, draw the computed units, or ns/op if there are none
+	if len(ret.ys) == 0 {
+		for _, e := range ret.exprs {
+			ret.ys = append(ret.ys, e.Name)
+		}
+	}
+	if len(ret.ys) == 0 {
+		ret.ys = []string{"ns/op"}
+	}
+	if ret.plot, err = internal.ToPlotType(o.Plot); err != nil {
+		return nil, errors.Wrapf(err, "unable to understand plot type %s", o.Plot)
+	}
+	if ret.xscale, err = internal.ToXScale(o.XScale); err != nil {
+		return nil, errors.Wrapf(err, "unable to understand x scale %s", o.XScale)
+	}
+	if ret.xsort, err = internal.ToSortOrder(o.XSort); err != nil {
+		return nil, errors.Wrapf(err, "unable to understand x sort %s", o.XSort)
+	}
+	if ret.groupsort, err = internal.ToSortOrder(o.GroupSort); err != nil {
+		return nil, errors.Wrapf(err, "unable to understand group sort %s", o.GroupSort)
+	}
+	if ret.agg, err = internal.ToAggregation(o.Agg); err != nil {
+		return nil, errors.Wrapf(err, "unable to understand aggregation %s", o.Agg)
+	}
+	// Counts are a number of samples, not an amount of y's unit
+	ret.count = o.Agg == "count"
+	if ret.errorBars, err = internal.ToErrorBars(o.ErrorBars); err != nil {
+		return nil, errors.Wrapf(err, "unable to understand error bars %s", o.ErrorBars)
+	}
+	if ret.count && ret.errorBars != nil {
+		return nil, errors.New("error bars are in y's unit, so cannot be drawn around a count")
+	}
+	if o.Normalize != "" {
+		kv := strings.SplitN(o.Normalize, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, errors.Errorf("unable to understand normalize %s: expect key=value", o.Normalize)
+		}
+		ret.normalizeKey, ret.normalizeValue = kv[0], kv[1]
+	}
+	if ret.format, err = renderers.ToFormat(o.Format, ""); err != nil {
+		return nil, errors.Wrapf(err, "unable to understand format %s", o.Format)
+	}
+	if ret.renderer, err = renderers.Renderer(ret.format); err != nil {
+		return nil, errors.Wrapf(err, "unable to find renderer of %s", ret.format)
+	}
+	if o.Width < 0 || o.Height < 0 || o.DPI < 0 {
+		return nil, errors.New("width, height and dpi cannot be negative")
+	}
+	ret.style = internal.ChartStyle{
+		Width:  o.Width,
+		Height: o.Height,
+		DPI:    o.DPI,
+	}
+	if ret.plot == internal.PlotTypeDelta && ret.baseline == nil {
+		return nil, errors.New("delta plots require a baseline")
+	}
+	if ret.plot == internal.PlotTypeDelta && len(ret.ys) != 1 {
+		return nil, errors.New("delta plots only support a single y unit")
+	}
+	return &ret, nil
+}
+
+// drawer turns benchmark output into a Chart
+type drawer struct {
+	benchreader internal.BenchmarkReader
+	filter      internal.Filter
+	grouper     internal.Grouper
+	log         internal.Logger
+}
+
+// newDrawer returns a drawer that logs to opts.Logger, or nowhere if it is nil
+func newDrawer(opts Options) drawer {
+	d := drawer{
+		log: internal.Logger{
+			Verbosity: opts.Verbosity,
+			Logger:    opts.Logger,
+		},
+	}
+	if d.log.Logger == nil {
+		d.log.Logger = log.New(ioutil.Discard, "", 0)
+	}
+	return d
+}
+
+// read reads the benchmarks of in, with the units of p.exprs added, that match p.filters and have any of units.  Nil
+// units means any unit.
+func (d *drawer) read(ctx context.Context, in io.Reader, p *parsedOptions, units []string) (internal.BenchmarkList, error) {
+	run, err := d.benchreader.ReadBenchmarks(in)
+	d.log.Log(3, "benchmarks: %s", run)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read benchmark data")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	results, err := internal.AddDerivedUnits(d.log, run.Results, p.exprs)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to compute y expressions")
+	}
+	if units == nil {
+		units = internal.BenchmarkList(results).AllUnits().Order
+	}
+	filteredResults := d.filter.FilterBenchmarks(results, p.filters, units...)
+	d.log.Log(3, "filtered Results: %s", filteredResults)
+	return filteredResults, nil
+}
+
+func (d *drawer) draw(ctx context.Context, in io.Reader, p *parsedOptions) (Chart, error) {
+	filteredResults, err := d.read(ctx, in, p, p.ys)
+	if err != nil {
+		return Chart{}, err
+	}
+	if p.plot == internal.PlotTypeDelta {
+		return d.drawDelta(ctx, p, filteredResults)
+	}
+	if len(filteredResults) == 0 {
+		return Chart{}, errors.New("no benchmarks match the filter and have every y unit")
+	}
+	uniqueKeys := filteredResults.UniqueValuesForKey(p.x)
+	d.log.Log(3, "uniqueKeys: %s", uniqueKeys)
+	scale := p.scale
+	if !scale.LogX {
+		numeric, err := p.xscale.IsNumeric(p.plot, uniqueKeys)
+		if err != nil {
+			return Chart{}, errors.Wrap(err, "unable to place x values")
+		}
+		scale.NumericX = numeric
+	}
+
+	var groupSet internal.OrderedStringSet
+	for _, g := range p.group {
+		groupSet.Add(g)
+	}
+	// When grouping by nothing, default to grouping by everything but the x axis.
+	if len(groupSet.Items) == 0 {
+		for _, k := range filteredResults.AllKeys().Order {
+			if k != p.x {
+				groupSet.Add(k)
+			}
+		}
+	}
+	// Each group is a line in our graph
+	d.log.Log(3, "groupSet: %v", groupSet)
+	grouped := d.grouper.GroupBenchmarks(filteredResults, groupSet)
+	d.log.Log(3, "grouped: %v", grouped)
+	baselineIndex := -1
+	if p.normalizeKey != "" {
+		for i, g := range grouped {
+			if g.Values.Contains(p.normalizeKey, p.normalizeValue) {
+				baselineIndex = i
+				break
+			}
+		}
+		if baselineIndex == -1 {
+			return Chart{}, errors.Errorf("no group has %s=%s to normalize against.  Is %s part of --group?", p.normalizeKey, p.normalizeValue, p.normalizeKey)
+		}
+	}
+	grouped.Normalize()
+	d.log.Log(3, "normalize: %v", grouped)
+
+	// Each unit of y is a panel of the same lines
+	panels := make([]internal.PlotPanel, 0, len(p.ys))
+	for _, y := range p.ys {
+		plotLines := make([]internal.PlotLine, 0, len(grouped))
+		for _, g := range grouped {
+			// For this line in our graph, compute the X Values
+			allVals := g.Results.ValuesByX(p.x, y, uniqueKeys)
+			pl := internal.PlotLine{
+				Name:   internal.NominalLineName(g.Values, grouped.AllSingleKey()),
+				Values: allVals,
+			}
+			d.log.Log(3, "nominal=%v plot=%v", pl.Name, pl)
+			plotLines = append(plotLines, pl)
+			d.log.Log(3, "plot line: %v", pl)
+		}
+		yLabel := y
+		if p.count {
+			yLabel = "count"
+		}
+		if baselineIndex != -1 {
+			plotLines = internal.RelativeLines(plotLines, plotLines[baselineIndex], p.agg)
+			yLabel += " relative to " + p.normalizeKey + "=" + p.normalizeValue
+			d.log.Log(3, "relative plot lines: %v", plotLines)
+		}
+		panels = append(panels, internal.PlotPanel{
+			Y:     yLabel,
+			Lines: plotLines,
+		})
+	}
+	xsort := p.xsort
+	if scale.NumericX || scale.LogX {
+		if xsort != internal.SortInput && xsort != internal.SortNumeric {
+			d.log.Log(1, "ignoring xsort: numeric x values are always sorted by number")
+		}
+		xsort = internal.SortNumeric
+	}
+	if uniqueKeys, panels, err = internal.SortX(xsort, p.agg, uniqueKeys, panels); err != nil {
+		return Chart{}, errors.Wrap(err, "unable to sort x values")
+	}
+	if panels, err = internal.SortLines(p.groupsort, p.agg, panels); err != nil {
+		return Chart{}, errors.Wrap(err, "unable to sort groups")
+	}
+	d.log.Log(3, "sorted uniqueKeys: %s", uniqueKeys)
+	// Relative values and counts have no unit to scale
+	scale.ReadableY = baselineIndex == -1 && !p.count
+	return d.chart(p, internal.Chart{
+		Title:       p.title,
+		X:           p.x,
+		XValues:     uniqueKeys,
+		Panels:      panels,
+		Type:        p.plot,
+		Aggregation: p.agg,
+		ErrorBars:   p.errorBars,
+		Scale:       scale,
+	}), nil
+}
+
+func (d *drawer) drawDelta(ctx context.Context, p *parsedOptions, candidate internal.BenchmarkList) (Chart, error) {
+	baseline, err := d.read(ctx, p.baseline, p, p.ys[:1])
+	if err != nil {
+		return Chart{}, errors.Wrap(err, "unable to read baseline")
+	}
+	deltas := internal.ComputeDeltas(baseline, candidate, p.ys[0], p.agg)
+	d.log.Log(3, "deltas: %v", deltas)
+	if len(deltas) == 0 {
+		return Chart{}, errors.New("no benchmarks are in both the baseline and the input")
+	}
+	return d.chart(p, internal.Chart{
+		Title:  p.title,
+		Type:   internal.PlotTypeDelta,
+		Deltas: deltas,
+		Unit:   p.ys[0],
+	}), nil
+}
+
+// chart finishes c with the format and style of p
+func (d *drawer) chart(p *parsedOptions, c internal.Chart) Chart {
+	c.Format = p.format
+	c.Style = p.style
+	return Chart{
+		chart:    c,
+		renderer: p.renderer,
+		log:      d.log,
+	}
+}
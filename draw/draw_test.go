@@ -0,0 +1,194 @@
+package draw
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/cep21/benchdraw/internal"
+	"github.com/stretchr/testify/require"
+)
+
+const testInput = `BenchmarkAdd/source=linear/digest=caio-8 	 1299153	       932 ns/op	      33 B/op
+BenchmarkAdd/source=linear/digest=segmentio-8	 1000000	      5674 ns/op	       8 B/op
+BenchmarkAdd/source=rand/digest=caio-8	 3973735	       327 ns/op	       0 B/op
+BenchmarkAdd/source=rand/digest=segmentio-8	 2212902	       827 ns/op	       8 B/op
+`
+
+func TestDraw(t *testing.T) {
+	chart, err := Draw(context.Background(), strings.NewReader(testInput), Options{
+		Filter: "BenchmarkAdd",
+		X:      "source",
+		Y:      []string{"ns/op", "B/op"},
+		Format: "csv",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "BenchmarkAdd", chart.Title())
+	require.Equal(t, "csv", chart.Format())
+	// Tables keep the original unit, and only pictures scale ns/op to the unit that best fits its values
+	require.Equal(t, []string{"ns/op", "B/op"}, chart.Units())
+	require.Equal(t, "µs/op", chart.Data().ReadablePanels()[0].Y)
+	var buf bytes.Buffer
+	require.NoError(t, chart.Render(&buf))
+	require.Equal(t, `unit,group,source=linear,source=linear samples,source=rand,source=rand samples
+ns/op,caio,932,1,327,1
+ns/op,segmentio,5674,1,827,1
+B/op,caio,33,1,0,1
+B/op,segmentio,8,1,8,1
+`, buf.String())
+}
+
+func TestDraw_count(t *testing.T) {
+	chart, err := Draw(context.Background(), strings.NewReader(testInput+testInput), Options{
+		X:      "source",
+		Agg:    "count",
+		Format: "csv",
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"count"}, chart.Units())
+	var buf bytes.Buffer
+	require.NoError(t, chart.Render(&buf))
+	require.Equal(t, `unit,group,source=linear,source=linear samples,source=rand,source=rand samples
+count,caio,2,2,2,2
+count,segmentio,2,2,2,2
+`, buf.String())
+}
+
+func TestDraw_delta(t *testing.T) {
+	chart, err := Draw(context.Background(), strings.NewReader(testInput), Options{
+		Plot:     "delta",
+		Baseline: strings.NewReader(strings.Replace(testInput, "932 ns/op", "466 ns/op", 1)),
+		Format:   "csv",
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"ns/op"}, chart.Units())
+	var buf bytes.Buffer
+	require.NoError(t, chart.Render(&buf))
+	require.Contains(t, buf.String(), "BenchmarkAdd/source=linear/digest=caio,466,932,100\n")
+}
+
+func TestDraw_errors(t *testing.T) {
+	drawErr := func(ctx context.Context, opts Options) func(t *testing.T) {
+		return func(t *testing.T) {
+			_, err := Draw(ctx, strings.NewReader(testInput), opts)
+			require.Error(t, err)
+		}
+	}
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	t.Run("plot", drawErr(context.Background(), Options{Plot: "pie"}))
+	t.Run("format", drawErr(context.Background(), Options{Format: "gif"}))
+	t.Run("counterrorbars", drawErr(context.Background(), Options{Agg: "count", ErrorBars: "stddev"}))
+	t.Run("nobaseline", drawErr(context.Background(), Options{Plot: "delta"}))
+	t.Run("canceled", drawErr(canceled, Options{}))
+	t.Run("nomatch", drawErr(context.Background(), Options{Filter: "BenchmarkMissing"}))
+	t.Run("nounit", drawErr(context.Background(), Options{Y: []string{"allocs/op"}}))
+}
+
+func TestToFormat(t *testing.T) {
+	format, err := ToFormat("", "chart.png")
+	require.NoError(t, err)
+	require.Equal(t, "png", format)
+	require.Contains(t, Formats(), format)
+}
+
+func TestRegister(t *testing.T) {
+	// Register into a registry of only built in formats, so "units" does not leak into other tests
+	defer func(global *internal.Registry) {
+		renderers = global
+	}(renderers)
+	renderers = &internal.Registry{}
+	Register("units", RendererFunc(func(log Logger, out io.Writer, chart ChartData) error {
+		for _, p := range chart.Panels {
+			if _, err := io.WriteString(out, p.Y+"\n"); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+	require.Contains(t, Formats(), "units")
+	format, err := ToFormat("", "chart.units")
+	require.NoError(t, err)
+	require.Equal(t, "units", format)
+	chart, err := Draw(context.Background(), strings.NewReader(testInput), Options{
+		X:      "source",
+		Y:      []string{"ns/op", "B/op"},
+		Format: format,
+	})
+	require.NoError(t, err)
+	require.Equal(t, PlotTypeBar, chart.Data().Type)
+	var buf bytes.Buffer
+	require.NoError(t, chart.Render(&buf))
+	require.Equal(t, "ns/op\nB/op\n", buf.String())
+}
+
+func TestAuto(t *testing.T) {
+	charts, err := Auto(context.Background(), strings.NewReader(testInput), Options{Y: []string{"B/op"}, Format: "csv"})
+	require.NoError(t, err)
+	require.Len(t, charts, 1)
+	require.Equal(t, "BenchmarkAdd_B_op", charts[0].Name)
+	require.Equal(t, Options{
+		Filter: "BenchmarkAdd",
+		Title:  "BenchmarkAdd",
+		X:      "source",
+		Group:  []string{"digest"},
+		Y:      []string{"B/op"},
+		Plot:   "bar",
+		Format: "csv",
+	}, charts[0].Options)
+}
+
+func TestList(t *testing.T) {
+	listing, err := List(context.Background(), strings.NewReader(testInput), Options{
+		Filter: "BenchmarkAdd/digest=caio",
+		YExpr:  []string{"B/ns=B/op / ns/op"},
+	})
+	require.NoError(t, err)
+	require.Len(t, listing, 1)
+	require.Equal(t, "BenchmarkAdd", listing[0].Name)
+	require.Equal(t, 2, listing[0].Results)
+	require.Equal(t, []UnitSummary{{Unit: "ns/op", Samples: 2}, {Unit: "B/op", Samples: 2}, {Unit: "B/ns", Samples: 2}}, listing[0].Units)
+	var buf bytes.Buffer
+	require.NoError(t, listing.Write(&buf))
+	require.Contains(t, buf.String(), "BenchmarkAdd (2 results)\n")
+
+	_, err = List(context.Background(), strings.NewReader(testInput), Options{YExpr: []string{"1 +"}})
+	require.Error(t, err)
+}
+
+func TestCheck(t *testing.T) {
+	baseline := strings.Replace(strings.Replace(testInput, "932 ns/op", "466 ns/op", 1), "5674 ns/op", "5500 ns/op", 1)
+	check := func(opts Options) (CheckReport, error) {
+		opts.Baseline = strings.NewReader(baseline)
+		return Check(context.Background(), strings.NewReader(testInput), opts)
+	}
+	report, err := check(Options{})
+	require.NoError(t, err)
+	require.Len(t, report.Results, 4)
+	require.Equal(t, 1, report.Regressed())
+	require.True(t, report.Results[0].Regressed)
+	require.Equal(t, "BenchmarkAdd/source=linear/digest=caio", report.Results[0].Name)
+	var buf bytes.Buffer
+	require.NoError(t, report.Write(&buf))
+	require.Contains(t, buf.String(), "1 of 4 benchmarks regressed\n")
+
+	report, err = check(Options{Threshold: "1,BenchmarkAdd/source=linear/digest=caio=100"})
+	require.NoError(t, err)
+	require.Equal(t, 1, report.Regressed())
+	require.Equal(t, "BenchmarkAdd/source=linear/digest=segmentio", report.Results[1].Name)
+	require.True(t, report.Results[1].Regressed)
+
+	report, err = check(Options{Y: []string{"ns/op", "B/op"}})
+	require.NoError(t, err)
+	require.Len(t, report.Results, 8)
+	require.Equal(t, "B/op", report.Results[4].Unit)
+
+	_, err = check(Options{Threshold: "abc"})
+	require.Error(t, err)
+	_, err = check(Options{Filter: "BenchmarkMissing"})
+	require.Error(t, err)
+	_, err = Check(context.Background(), strings.NewReader(testInput), Options{})
+	require.Error(t, err)
+}
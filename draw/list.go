@@ -0,0 +1,41 @@
+package draw
+
+import (
+	"context"
+	"io"
+
+	"github.com/cep21/benchdraw/internal"
+	"github.com/pkg/errors"
+)
+
+// BenchmarkSummary is what a top level benchmark has to draw: its keys, each key's values and its units
+type BenchmarkSummary = internal.BenchmarkSummary
+
+// KeySummary is every value of a key, in the order they are first seen
+type KeySummary = internal.KeySummary
+
+// UnitSummary is how many results have a unit
+type UnitSummary = internal.UnitSummary
+
+// Listing is a summary of each top level benchmark, in the order they are first seen
+type Listing []BenchmarkSummary
+
+// Write writes each summary as text, with a line for each key and its values and each unit and its samples
+func (l Listing) Write(out io.Writer) error {
+	return internal.WriteSummaries(out, l)
+}
+
+// List reads benchmark output from in and summarizes each top level benchmark that matches opts.Filter, including
+// the units of opts.YExpr.  It is what to pick X, Group and Y from.
+func List(ctx context.Context, in io.Reader, opts Options) (Listing, error) {
+	p, err := opts.parse()
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid options")
+	}
+	d := newDrawer(opts)
+	filtered, err := d.read(ctx, in, p, nil)
+	if err != nil {
+		return nil, err
+	}
+	return internal.SummarizeBenchmarks(filtered), nil
+}
@@ -0,0 +1,51 @@
+package draw
+
+import "github.com/cep21/benchdraw/internal"
+
+// Renderer draws ChartData in a format.  Register one to add a format, or to replace how a built in format is drawn.
+type Renderer = internal.Renderer
+
+// RendererFunc allows a function to be a Renderer
+type RendererFunc = internal.RendererFunc
+
+// Logger is how a Renderer logs.  Renderers log what they cannot draw, like log scales in term output, at verbosity 1.
+type Logger = internal.Logger
+
+// ChartData is everything a Renderer needs to draw one chart.  Delta charts draw Deltas and every other chart draws
+// Panels.
+type ChartData = internal.Chart
+
+// ChartStyle is how big a chart is drawn
+type ChartStyle = internal.ChartStyle
+
+// PlotPanel is every line of one y unit
+type PlotPanel = internal.PlotPanel
+
+// PlotLine is the values of one group at each x value, in the order of ChartData.XValues
+type PlotLine = internal.PlotLine
+
+// PlotType is how a chart is drawn
+type PlotType = internal.PlotType
+
+// The plot types of ChartData.Type
+const (
+	PlotTypeBar   = internal.PlotTypeBar
+	PlotTypeLine  = internal.PlotTypeLine
+	PlotTypeBox   = internal.PlotTypeBox
+	PlotTypeDelta = internal.PlotTypeDelta
+)
+
+// AxisScale is how values are placed on the x and y axis
+type AxisScale = internal.AxisScale
+
+// Aggregation turns every value at an x into the one value drawn
+type Aggregation = internal.Aggregation
+
+// ErrorBars returns the low and high of an error bar around the value drawn at an x
+type ErrorBars = internal.ErrorBars
+
+// Delta is the change of one benchmark between a baseline and the input
+type Delta = internal.Delta
+
+// OrderedStringSet is a set of strings that remembers the order they were added
+type OrderedStringSet = internal.OrderedStringSet
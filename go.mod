@@ -31,7 +31,7 @@ require (
 	github.com/stretchr/testify v1.2.2
 	github.com/ultraware/funlen v0.0.2 // indirect
 	golang.org/x/image v0.0.0-20190910094157-69e4b8554b2a // indirect
-	golang.org/x/sys v0.0.0-20190919044723-0c1ff786ef13 // indirect
+	golang.org/x/sys v0.0.0-20190919044723-0c1ff786ef13
 	golang.org/x/text v0.3.2 // indirect
 	golang.org/x/tools v0.0.0-20190919180025-928b73f71f9b // indirect
 	gonum.org/v1/netlib v0.0.0-20190331212654-76723241ea4e // indirect
@@ -0,0 +1,117 @@
+package internal
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gonum.org/v1/plot/plotter"
+)
+
+// Aggregation reduces all the values for a single x index into the one value we draw.  It is never called with an
+// empty list.
+type Aggregation func(vals []float64) float64
+
+var aggregations = map[string]Aggregation{
+	"mean":    meanAggregation,
+	"median":  medianAggregation,
+	"min":     minAggregation,
+	"max":     maxAggregation,
+	"sum":     sumAggregation,
+	"geomean": geomeanAggregation,
+	"count":   countAggregation,
+}
+
+// ToAggregation converts a string name to a known aggregation.  Empty means mean.  Besides the names in aggregations,
+// pN (for example p90 or p99.9) is the Nth percentile.
+func ToAggregation(s string) (Aggregation, error) {
+	if s == "" {
+		return meanAggregation, nil
+	}
+	if agg, exists := aggregations[s]; exists {
+		return agg, nil
+	}
+	if strings.HasPrefix(s, "p") {
+		n, err := strconv.ParseFloat(s[1:], 64)
+		if err != nil || math.IsNaN(n) || n < 0 || n > 100 {
+			return nil, errors.New("invalid percentile aggregation " + s)
+		}
+		return percentileAggregation(n), nil
+	}
+	return nil, errors.New("unknown aggregation " + s)
+}
+
+func aggregatePlotterValues(f [][]float64, aggregation Aggregation) plotter.XYs {
+	var ret plotter.XYs
+	for i, x := range f {
+		y := 0.0
+		if len(x) > 0 {
+			y = aggregation(x)
+		}
+		ret = append(ret, plotter.XY{
+			X: float64(i),
+			Y: y,
+		})
+	}
+	return ret
+}
+
+func meanAggregation(vals []float64) float64 {
+	return sumAggregation(vals) / float64(len(vals))
+}
+
+func sumAggregation(vals []float64) float64 {
+	sum := 0.0
+	for _, v := range vals {
+		sum += v
+	}
+	return sum
+}
+
+func minAggregation(vals []float64) float64 {
+	ret := vals[0]
+	for _, v := range vals[1:] {
+		ret = math.Min(ret, v)
+	}
+	return ret
+}
+
+func maxAggregation(vals []float64) float64 {
+	ret := vals[0]
+	for _, v := range vals[1:] {
+		ret = math.Max(ret, v)
+	}
+	return ret
+}
+
+func countAggregation(vals []float64) float64 {
+	return float64(len(vals))
+}
+
+// geomeanAggregation is only meaningful for positive values, which most benchmark units are.
+func geomeanAggregation(vals []float64) float64 {
+	logSum := 0.0
+	for _, v := range vals {
+		logSum += math.Log(v)
+	}
+	return math.Exp(logSum / float64(len(vals)))
+}
+
+func medianAggregation(vals []float64) float64 {
+	return percentileAggregation(50)(vals)
+}
+
+// percentileAggregation linearly interpolates between the two closest ranks of the sorted values
+func percentileAggregation(n float64) Aggregation {
+	return func(vals []float64) float64 {
+		sorted := make([]float64, len(vals))
+		copy(sorted, vals)
+		sort.Float64s(sorted)
+		rank := n / 100 * float64(len(sorted)-1)
+		lower := int(math.Floor(rank))
+		upper := int(math.Ceil(rank))
+		return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+	}
+}
@@ -0,0 +1,54 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestToAggregation(t *testing.T) {
+	aggEqual := func(name string, vals []float64, expected float64) func(t *testing.T) {
+		return func(t *testing.T) {
+			agg, err := ToAggregation(name)
+			require.NoError(t, err)
+			require.InDelta(t, expected, agg(vals), 0.0001)
+		}
+	}
+	t.Run("default", aggEqual("", []float64{1, 2, 6}, 3))
+	t.Run("mean", aggEqual("mean", []float64{1, 2, 6}, 3))
+	t.Run("median", aggEqual("median", []float64{6, 1, 2}, 2))
+	t.Run("mediantwo", aggEqual("median", []float64{1, 2}, 1.5))
+	t.Run("min", aggEqual("min", []float64{3, 1, 2}, 1))
+	t.Run("max", aggEqual("max", []float64{3, 1, 2}, 3))
+	t.Run("sum", aggEqual("sum", []float64{3, 1, 2}, 6))
+	t.Run("count", aggEqual("count", []float64{3, 1, 2}, 3))
+	t.Run("geomean", aggEqual("geomean", []float64{1, 4, 16}, 4))
+	t.Run("p0", aggEqual("p0", []float64{3, 1, 2}, 1))
+	t.Run("p100", aggEqual("p100", []float64{3, 1, 2}, 3))
+	t.Run("p90", aggEqual("p90", []float64{10, 20, 30, 40, 50, 60, 70, 80, 90, 100, 110}, 100))
+	t.Run("p99.5", aggEqual("p99.5", []float64{0, 100}, 99.5))
+	t.Run("single", aggEqual("p99", []float64{7}, 7))
+	mustErr := func(name string) func(t *testing.T) {
+		return func(t *testing.T) {
+			_, err := ToAggregation(name)
+			require.Error(t, err)
+		}
+	}
+	t.Run("unknown", mustErr("average"))
+	t.Run("badpercentile", mustErr("pabc"))
+	t.Run("bigpercentile", mustErr("p101"))
+	t.Run("nanpercentile", mustErr("pNaN"))
+}
+
+func TestAggregatePlotterValues(t *testing.T) {
+	xys := aggregatePlotterValues([][]float64{{1, 3}, {}, {5}}, meanAggregation)
+	require.Equal(t, 3, xys.Len())
+	x, y := xys.XY(0)
+	require.Equal(t, 0.0, x)
+	require.Equal(t, 2.0, y)
+	_, y = xys.XY(1)
+	require.Equal(t, 0.0, y)
+	x, y = xys.XY(2)
+	require.Equal(t, 2.0, x)
+	require.Equal(t, 5.0, y)
+}
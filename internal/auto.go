@@ -0,0 +1,90 @@
+package internal
+
+import (
+	"strings"
+)
+
+// AutoChart is a chart AutoCharts picks from the data
+type AutoChart struct {
+	// Benchmark is the top level benchmark name the chart draws, like BenchmarkDecode
+	Benchmark string
+	X         string
+	// Group are the keys, besides X, that vary between the benchmark's results
+	Group []string
+	Unit  string
+	Plot  PlotType
+}
+
+// AutoCharts picks a chart for each top level benchmark name and unit of results.  X is the numeric key, which includes
+// procs, with the most values, or else the key with the most values, and the chart is grouped by every other key that
+// varies.  Numeric x values are drawn as lines and the rest as bars.
+func AutoCharts(results BenchmarkList) []AutoChart {
+	var names OrderedStringSet
+	byName := make(map[string]BenchmarkList)
+	for _, r := range results {
+		name := TopLevelName(r.Name)
+		names.Add(name)
+		byName[name] = append(byName[name], r)
+	}
+	ret := make([]AutoChart, 0, len(names.Order))
+	for _, name := range names.Order {
+		list := byName[name]
+		x, numeric, group := pickAutoAxes(list)
+		pt := PlotTypeBar
+		if numeric {
+			pt = PlotTypeLine
+		}
+		for _, unit := range list.AllUnits().Order {
+			ret = append(ret, AutoChart{
+				Benchmark: name,
+				X:         x,
+				Group:     group,
+				Unit:      unit,
+				Plot:      pt,
+			})
+		}
+	}
+	return ret
+}
+
+// TopLevelName is a benchmark name without its sub benchmarks or -N GOMAXPROCS suffix
+func TopLevelName(name string) string {
+	name, _ = splitProcs(name)
+	return strings.SplitN(name, "/", 2)[0]
+}
+
+// pickAutoAxes returns the x key of a list of benchmarks, if its values are numbers, and the keys to group by
+func pickAutoAxes(list BenchmarkList) (string, bool, []string) {
+	var varying []string
+	values := make(map[string]OrderedStringSet)
+	for _, k := range list.AllKeys().Order {
+		values[k] = list.UniqueValuesForKey(k)
+		if len(values[k].Order) > 1 {
+			varying = append(varying, k)
+		}
+	}
+	x, numeric := "", false
+	for _, k := range varying {
+		isNumeric, err := XScaleAuto.IsNumeric(PlotTypeLine, values[k])
+		if err == nil && isNumeric && (!numeric || len(values[k].Order) > len(values[x].Order)) {
+			x, numeric = k, true
+		}
+	}
+	if x == "" {
+		for _, k := range varying {
+			if x == "" || len(values[k].Order) > len(values[x].Order) {
+				x = k
+			}
+		}
+	}
+	if x == "" {
+		x = ProcsKey
+	}
+	group := make([]string, 0, len(varying))
+	for _, k := range varying {
+		if k != x {
+			group = append(group, k)
+		}
+	}
+	return x, numeric, group
+}
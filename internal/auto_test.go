@@ -0,0 +1,60 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/cep21/benchparse"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutoCharts(t *testing.T) {
+	result := func(name string, units ...string) benchparse.BenchmarkResult {
+		ret := benchparse.BenchmarkResult{Name: name}
+		for _, u := range units {
+			ret.Values = append(ret.Values, benchparse.ValueUnitPair{Value: 1, Unit: u})
+		}
+		return ret
+	}
+	t.Run("numeric", func(t *testing.T) {
+		charts := AutoCharts(BenchmarkList{
+			result("BenchmarkDecode/text=digits/size=1e4-8", "ns/op", "B/op"),
+			result("BenchmarkDecode/text=digits/size=1e5-8", "ns/op", "B/op"),
+			result("BenchmarkDecode/text=twain/size=1e4-8", "ns/op", "B/op"),
+			result("BenchmarkDecode/text=twain/size=1e5-8", "ns/op", "B/op"),
+			result("BenchmarkEncode-8", "ns/op"),
+		})
+		require.Equal(t, []AutoChart{
+			{Benchmark: "BenchmarkDecode", X: "size", Group: []string{"text"}, Unit: "ns/op", Plot: PlotTypeLine},
+			{Benchmark: "BenchmarkDecode", X: "size", Group: []string{"text"}, Unit: "B/op", Plot: PlotTypeLine},
+			{Benchmark: "BenchmarkEncode", X: ProcsKey, Group: []string{}, Unit: "ns/op", Plot: PlotTypeBar},
+		}, charts)
+	})
+	t.Run("procs", func(t *testing.T) {
+		charts := AutoCharts(BenchmarkList{
+			result("BenchmarkParallel/source=a", "ns/op"),
+			result("BenchmarkParallel/source=a-2", "ns/op"),
+			result("BenchmarkParallel/source=a-4", "ns/op"),
+			result("BenchmarkParallel/source=b-4", "ns/op"),
+		})
+		require.Equal(t, []AutoChart{
+			{Benchmark: "BenchmarkParallel", X: ProcsKey, Group: []string{"source"}, Unit: "ns/op", Plot: PlotTypeLine},
+		}, charts)
+	})
+	t.Run("nominal", func(t *testing.T) {
+		charts := AutoCharts(BenchmarkList{
+			result("BenchmarkAdd/source=linear/digest=caio-8", "ns/op"),
+			result("BenchmarkAdd/source=rand/digest=caio-8", "ns/op"),
+			result("BenchmarkAdd/source=normal/digest=caio-8", "ns/op"),
+			result("BenchmarkAdd/source=linear/digest=segmentio-8", "ns/op"),
+		})
+		require.Equal(t, []AutoChart{
+			{Benchmark: "BenchmarkAdd", X: "source", Group: []string{"digest"}, Unit: "ns/op", Plot: PlotTypeBar},
+		}, charts)
+	})
+}
+
+func TestTopLevelName(t *testing.T) {
+	require.Equal(t, "BenchmarkDecode", TopLevelName("BenchmarkDecode/text=digits-8"))
+	require.Equal(t, "BenchmarkDecode", TopLevelName("BenchmarkDecode-8"))
+	require.Equal(t, "BenchmarkDecode", TopLevelName("BenchmarkDecode"))
+}
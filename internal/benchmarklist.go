@@ -1,6 +1,11 @@
 package internal
 
-import "github.com/cep21/benchparse"
+import (
+	"strings"
+	"unicode"
+
+	"github.com/cep21/benchparse"
+)
 
 // BenchmarkList is a list of benchmarks
 type BenchmarkList []benchparse.BenchmarkResult
@@ -18,15 +23,65 @@ func (b BenchmarkList) UniqueValuesForKey(key string) OrderedStringSet {
 	return ret
 }
 
+// AllKeys returns every key of every benchmark in this list, in the order they are first seen
+func (b BenchmarkList) AllKeys() OrderedStringSet {
+	var ret OrderedStringSet
+	for _, b := range b {
+		for _, k := range makeKeys(b).Order {
+			ret.Add(k)
+		}
+	}
+	return ret
+}
+
+// AllUnits returns every unit of every benchmark in this list, in the order they are first seen
+func (b BenchmarkList) AllUnits() OrderedStringSet {
+	var ret OrderedStringSet
+	for _, b := range b {
+		for _, v := range b.Values {
+			ret.Add(v.Unit)
+		}
+	}
+	return ret
+}
+
+// ProcsKey is the key for the GOMAXPROCS a benchmark ran with.  It comes from the -N suffix go test adds to benchmark
+// names, so it is the same for every benchmark unless you run with something like -cpu=1,2,4,8.
+const ProcsKey = "procs"
+
+// makeKeys returns every key/value pair that describes a benchmark result: the configuration keys in effect for the
+// result (like goos, pkg or cpu) followed by the key/value tags of the benchmark's name, then ProcsKey.  If a name tag
+// and a configuration key have the same key, the name tag wins since it is more specific to this result.  Either wins
+// over ProcsKey.
 func makeKeys(r benchparse.BenchmarkResult) OrderedStringStringMap {
+	var procs string
+	r.Name, procs = splitProcs(r.Name)
 	nameKeys := r.AllKeyValuePairs()
 	var ret OrderedStringStringMap
 	for _, k := range nameKeys.Order {
 		ret.Insert(k, nameKeys.Contents[k])
 	}
+	if _, exists := ret.Values[ProcsKey]; !exists {
+		ret.Insert(ProcsKey, procs)
+	}
 	return ret
 }
 
+// splitProcs splits the -N GOMAXPROCS suffix from a benchmark name.  go test does not add a suffix when GOMAXPROCS is
+// 1.
+func splitProcs(name string) (string, string) {
+	lastDash := strings.LastIndex(name, "-")
+	if lastDash == -1 || lastDash == len(name)-1 {
+		return name, "1"
+	}
+	for _, r := range name[lastDash+1:] {
+		if !unicode.IsDigit(r) {
+			return name, "1"
+		}
+	}
+	return name[:lastDash], name[lastDash+1:]
+}
+
 // ValuesByX returns all values in this group for a given x, ordered by all possible x values.  For example,
 // if xDim='name', then allValues will contain all the values for xDim='name' and in the order we want to render
 // them.  So if there are two names, Jack and John, then allValues=[Jack,John].  Unit is the benchmark value's unit
@@ -82,6 +82,46 @@ func TestBenchmarkList_UniqueValuesForKey(t *testing.T) {
 	require.Equal(t, makeSet("digest", "sign"), b2.UniqueValuesForKey("type"))
 }
 
+func TestBenchmarkList_AllKeys(t *testing.T) {
+	bl := BenchmarkList(mustParse(run1).Results)
+	require.Equal(t, makeSet("goos", "goarch", "pkg", "BenchmarkTdigest_TotalSize", "digest", "procs", "BenchmarkTdigest_Add", "source"), bl.AllKeys())
+	b2 := BenchmarkList(mustParse(run2).Results)
+	require.Equal(t, makeSet("unused", "BenchmarkTest", "name", "type", "procs"), b2.AllKeys())
+}
+
+func TestBenchmarkList_AllUnits(t *testing.T) {
+	bl := BenchmarkList(mustParse(run2).Results)
+	require.Equal(t, "ns/op", bl.AllUnits().Order[0])
+	require.Empty(t, BenchmarkList(nil).AllUnits().Order)
+}
+
+func TestMakeKeys(t *testing.T) {
+	res := mustParse(run2).Results
+	// The name tag name=bob wins over the configuration name: john
+	keys := makeKeys(res[0])
+	require.Equal(t, "bob", keys.Values["name"])
+	require.Equal(t, "unused", keys.Values["unused"])
+	require.Equal(t, "john", makeKeys(res[2]).Values["name"])
+}
+
+const procsRun = `
+BenchmarkTest/name=bob 1 10 ns/op
+BenchmarkTest/name=bob-2 1 20 ns/op
+BenchmarkTest/caio-4 1 30 ns/op
+BenchmarkTest/procs=3-8 1 40 ns/op
+`
+
+func TestMakeKeys_Procs(t *testing.T) {
+	res := mustParse(procsRun).Results
+	require.Equal(t, makeMap("BenchmarkTest", "", "name", "bob", "procs", "1"), makeKeys(res[0]))
+	require.Equal(t, makeMap("BenchmarkTest", "", "name", "bob", "procs", "2"), makeKeys(res[1]))
+	// The suffix is removed from keys without a value too
+	require.Equal(t, makeMap("BenchmarkTest", "", "caio", "", "procs", "4"), makeKeys(res[2]))
+	// A name tag wins over the suffix
+	require.Equal(t, makeMap("BenchmarkTest", "", "procs", "3"), makeKeys(res[3]))
+	require.Equal(t, makeSet("1", "2", "4", "3"), BenchmarkList(res).UniqueValuesForKey(ProcsKey))
+}
+
 func TestBenchmarkList_ValuesByX(t *testing.T) {
 	bl := BenchmarkList(mustParse(run2).Results)
 	require.Equal(t, [][]float64{
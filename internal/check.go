@@ -0,0 +1,152 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Thresholds are the largest regression, in percent, each benchmark is allowed
+type Thresholds struct {
+	// Default is the threshold of benchmarks without their own
+	Default float64
+	// ByName is the threshold of a benchmark name, or of every benchmark whose name starts with it
+	ByName map[string]float64
+}
+
+// ToThresholds parses a comma separated list of thresholds.  A number is the default threshold and name=number is the
+// threshold of a benchmark name, like 5,BenchmarkDecode=10,BenchmarkDecode/level=best=20.
+func ToThresholds(s string, defaultThreshold float64) (Thresholds, error) {
+	ret := Thresholds{
+		Default: defaultThreshold,
+	}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value := "", part
+		if idx := strings.LastIndex(part, "="); idx != -1 {
+			name, value = part[:idx], part[idx+1:]
+		}
+		v, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+		if err != nil || v < 0 || math.IsNaN(v) {
+			return Thresholds{}, errors.Errorf("threshold %s is not a percent of 0 or more", part)
+		}
+		if name == "" {
+			ret.Default = v
+			continue
+		}
+		if ret.ByName == nil {
+			ret.ByName = make(map[string]float64)
+		}
+		ret.ByName[name] = v
+	}
+	return ret, nil
+}
+
+// Threshold returns the threshold of a benchmark name: that of the name, or of the longest / separated prefix of the
+// name with one, or else the default.
+func (t Thresholds) Threshold(name string) float64 {
+	for prefix := name; prefix != ""; {
+		if v, exists := t.ByName[prefix]; exists {
+			return v
+		}
+		idx := strings.LastIndex(prefix, "/")
+		if idx == -1 {
+			break
+		}
+		prefix = prefix[:idx]
+	}
+	return t.Default
+}
+
+// CheckResult is a delta checked against its threshold
+type CheckResult struct {
+	Delta
+	Unit      string
+	Threshold float64
+	// Regressed is true if the delta got worse by more than Threshold percent
+	Regressed bool
+}
+
+// CheckDeltas checks each delta of unit against its threshold.  Only changes for the worse, which for most units is an
+// increase but for throughput units like MB/s is a decrease, are regressions.
+func CheckDeltas(deltas []Delta, unit string, t Thresholds) []CheckResult {
+	ret := make([]CheckResult, 0, len(deltas))
+	for _, d := range deltas {
+		worse := d.PercentChange()
+		if HigherIsBetter(unit) {
+			worse = -worse
+		}
+		threshold := t.Threshold(d.Name)
+		ret = append(ret, CheckResult{
+			Delta:     d,
+			Unit:      unit,
+			Threshold: threshold,
+			Regressed: worse > threshold,
+		})
+	}
+	return ret
+}
+
+// WriteCheckReport writes a line for each result, regressions first, followed by how many regressed
+func WriteCheckReport(out io.Writer, results []CheckResult) error {
+	ordered := make([]CheckResult, 0, len(results))
+	regressed := 0
+	for _, r := range results {
+		if r.Regressed {
+			ordered = append(ordered, r)
+			regressed++
+		}
+	}
+	for _, r := range results {
+		if !r.Regressed {
+			ordered = append(ordered, r)
+		}
+	}
+	rows := make([][]string, 0, len(ordered))
+	for _, r := range ordered {
+		status := "ok"
+		if r.Regressed {
+			status = "REGRESSED"
+		}
+		rows = append(rows, []string{
+			status,
+			r.Name,
+			r.Unit,
+			formatTermValue(r.Baseline) + " -> " + formatTermValue(r.Candidate),
+			fmt.Sprintf("%+.1f%%", r.PercentChange()),
+			fmt.Sprintf("(threshold %g%%)", r.Threshold),
+		})
+	}
+	var sb strings.Builder
+	widths := make([]int, 6)
+	for i := range widths {
+		column := make([]string, 0, len(rows))
+		for _, row := range rows {
+			column = append(column, row[i])
+		}
+		widths[i] = maxWidth(column)
+	}
+	for _, row := range rows {
+		cells := make([]string, 0, len(row))
+		for i, c := range row {
+			if i == len(row)-1 {
+				cells = append(cells, c)
+				continue
+			}
+			cells = append(cells, padRight(c, widths[i]))
+		}
+		sb.WriteString(strings.Join(cells, "  ") + "\n")
+	}
+	sb.WriteString(fmt.Sprintf("%d of %d benchmarks regressed\n", regressed, len(results)))
+	if _, err := io.WriteString(out, sb.String()); err != nil {
+		return errors.Wrap(err, "unable to write report")
+	}
+	return nil
+}
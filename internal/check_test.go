@@ -0,0 +1,98 @@
+package internal
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestToThresholds(t *testing.T) {
+	th, err := ToThresholds("", 5)
+	require.NoError(t, err)
+	require.Equal(t, Thresholds{Default: 5}, th)
+
+	th, err = ToThresholds("10%, BenchmarkDecode=20,BenchmarkDecode/level=best=1.5", 5)
+	require.NoError(t, err)
+	require.Equal(t, Thresholds{
+		Default: 10,
+		ByName: map[string]float64{
+			"BenchmarkDecode":            20,
+			"BenchmarkDecode/level=best": 1.5,
+		},
+	}, th)
+
+	for _, bad := range []string{"abc", "-1", "BenchmarkDecode=", "NaN", "BenchmarkDecode=x"} {
+		_, err := ToThresholds(bad, 5)
+		require.Error(t, err, bad)
+	}
+}
+
+func TestThresholds_Threshold(t *testing.T) {
+	th := Thresholds{
+		Default: 5,
+		ByName: map[string]float64{
+			"BenchmarkDecode":            20,
+			"BenchmarkDecode/level=best": 1,
+		},
+	}
+	require.Equal(t, 5.0, th.Threshold("BenchmarkEncode/level=best"))
+	require.Equal(t, 5.0, th.Threshold("BenchmarkDecodeFast"))
+	require.Equal(t, 20.0, th.Threshold("BenchmarkDecode"))
+	require.Equal(t, 20.0, th.Threshold("BenchmarkDecode/level=fast"))
+	require.Equal(t, 1.0, th.Threshold("BenchmarkDecode/level=best"))
+	require.Equal(t, 1.0, th.Threshold("BenchmarkDecode/level=best/size=10"))
+}
+
+func TestCheckDeltas(t *testing.T) {
+	base := BenchmarkList(mustParse(deltaBase).Results)
+	candidate := BenchmarkList(mustParse(deltaCandidate).Results)
+	th := Thresholds{Default: 5}
+
+	results := CheckDeltas(ComputeDeltas(base, candidate, "ns/op", meanAggregation), "ns/op", th)
+	require.Len(t, results, 2)
+	require.True(t, results[0].Regressed)
+	require.False(t, results[1].Regressed)
+	require.Equal(t, "ns/op", results[0].Unit)
+	require.Equal(t, 5.0, results[0].Threshold)
+
+	// More MB/s is better, so john's 20% increase is not a regression
+	results = CheckDeltas(ComputeDeltas(base, candidate, "MB/s", meanAggregation), "MB/s", th)
+	require.Len(t, results, 2)
+	require.False(t, results[0].Regressed)
+	require.False(t, results[1].Regressed)
+	results = CheckDeltas(ComputeDeltas(candidate, base, "MB/s", meanAggregation), "MB/s", th)
+	for _, r := range results {
+		require.Equal(t, r.Name == "BenchmarkTest/name=john", r.Regressed, r.Name)
+	}
+
+	th.ByName = map[string]float64{"BenchmarkTest/name=bob": 60}
+	results = CheckDeltas(ComputeDeltas(base, candidate, "ns/op", meanAggregation), "ns/op", th)
+	require.False(t, results[0].Regressed)
+	require.Equal(t, 60.0, results[0].Threshold)
+}
+
+func TestCheckDeltas_bareName(t *testing.T) {
+	base := BenchmarkList(mustParse("BenchmarkX-8 1 10 ns/op\n").Results)
+	candidate := BenchmarkList(mustParse("BenchmarkX-8 1 15 ns/op\n").Results)
+	results := CheckDeltas(ComputeDeltas(base, candidate, "ns/op", meanAggregation), "ns/op", Thresholds{
+		Default: 5,
+		ByName:  map[string]float64{"BenchmarkX": 60},
+	})
+	require.Len(t, results, 1)
+	require.Equal(t, "BenchmarkX", results[0].Name)
+	require.Equal(t, 60.0, results[0].Threshold)
+	require.False(t, results[0].Regressed)
+}
+
+func TestWriteCheckReport(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteCheckReport(&buf, []CheckResult{
+		{Delta: Delta{Name: "BenchmarkTest/name=john", Baseline: 20, Candidate: 19}, Unit: "ns/op", Threshold: 5},
+		{Delta: Delta{Name: "BenchmarkTest/name=bob", Baseline: 20, Candidate: 30}, Unit: "ns/op", Threshold: 5, Regressed: true},
+	}))
+	require.Equal(t, `REGRESSED  BenchmarkTest/name=bob   ns/op  20 -> 30  +50.0%  (threshold 5%)
+ok         BenchmarkTest/name=john  ns/op  20 -> 19  -5.0%   (threshold 5%)
+1 of 2 benchmarks regressed
+`, buf.String())
+}
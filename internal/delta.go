@@ -0,0 +1,111 @@
+package internal
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/cep21/benchparse"
+)
+
+// Delta is the change of one benchmark between a baseline run and a candidate run
+type Delta struct {
+	// Name is the benchmark's name and tags, without the -N GOMAXPROCS suffix.  If the deltas are of benchmarks run
+	// with more than one GOMAXPROCS, it ends with a procs tag to tell them apart.
+	Name string
+	// Baseline is the aggregated value of the benchmark in the baseline run
+	Baseline float64
+	// Candidate is the aggregated value of the benchmark in the candidate run
+	Candidate float64
+}
+
+// PercentChange returns how much Candidate changed from Baseline, as a percent of Baseline.  A change from a zero
+// Baseline is infinite.
+func (d Delta) PercentChange() float64 {
+	if d.Candidate == d.Baseline {
+		return 0
+	}
+	return (d.Candidate - d.Baseline) / math.Abs(d.Baseline) * 100
+}
+
+// HigherIsBetter returns true if a larger value of unit is an improvement.  Go's default units are all costs, but
+// throughput units like MB/s are not.
+func HigherIsBetter(unit string) bool {
+	return strings.HasSuffix(unit, "/s")
+}
+
+// deltaKey is what we match baseline and candidate benchmarks with: the benchmark's name and tags, without the -N
+// suffix, and its procs.  Configuration is ignored, since it is expected to differ between runs.
+type deltaKey struct {
+	name  string
+	procs string
+}
+
+func makeDeltaKey(r benchparse.BenchmarkResult) deltaKey {
+	r.Configuration = nil
+	keys := makeKeys(r)
+	parts := make([]string, 0, len(keys.Order))
+	for _, k := range keys.Order {
+		if k == ProcsKey {
+			continue
+		}
+		if v := keys.Values[k]; v != "" {
+			parts = append(parts, k+"="+v)
+		} else {
+			parts = append(parts, k)
+		}
+	}
+	return deltaKey{
+		name:  strings.Join(parts, "/"),
+		procs: keys.Values[ProcsKey],
+	}
+}
+
+func valuesByDeltaKey(in BenchmarkList, unit string) (map[deltaKey][]float64, []deltaKey) {
+	ret := make(map[deltaKey][]float64)
+	order := make([]deltaKey, 0, len(in))
+	for _, b := range in {
+		val, exists := b.ValueByUnit(unit)
+		if !exists {
+			continue
+		}
+		key := makeDeltaKey(b)
+		if _, exists := ret[key]; !exists {
+			order = append(order, key)
+		}
+		ret[key] = append(ret[key], val)
+	}
+	return ret, order
+}
+
+// ComputeDeltas matches each benchmark in candidate to the benchmark with the same name, tags and procs in baseline,
+// and returns the change of unit between them.  Multiple values for the same benchmark are combined with agg.  Benchmarks
+// that are not in both runs are ignored.  The returned list is sorted by the size of the change, largest first.
+func ComputeDeltas(baseline BenchmarkList, candidate BenchmarkList, unit string, agg Aggregation) []Delta {
+	baseValues, _ := valuesByDeltaKey(baseline, unit)
+	candidateValues, order := valuesByDeltaKey(candidate, unit)
+	matched := make([]deltaKey, 0, len(order))
+	var procs OrderedStringSet
+	for _, key := range order {
+		if _, exists := baseValues[key]; exists {
+			matched = append(matched, key)
+			procs.Add(key.procs)
+		}
+	}
+	ret := make([]Delta, 0, len(matched))
+	for _, key := range matched {
+		name := key.name
+		if len(procs.Order) > 1 {
+			name += "/" + ProcsKey + "=" + key.procs
+		}
+		ret = append(ret, Delta{
+			Name:      name,
+			Baseline:  agg(baseValues[key]),
+			Candidate: agg(candidateValues[key]),
+		})
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		return math.Abs(ret[i].PercentChange()) > math.Abs(ret[j].PercentChange())
+	})
+	return ret
+}
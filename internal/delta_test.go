@@ -0,0 +1,71 @@
+package internal
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const deltaBase = `
+commit: abc
+BenchmarkTest/name=bob-8 1 10 ns/op 10 MB/s
+BenchmarkTest/name=bob-8 1 30 ns/op 10 MB/s
+BenchmarkTest/name=john-8 1 20 ns/op 10 MB/s
+BenchmarkTest/name=jane-8 1 20 ns/op 10 MB/s
+`
+
+const deltaCandidate = `
+commit: def
+BenchmarkTest/name=john-8 1 19 ns/op 12 MB/s
+BenchmarkTest/name=bob-8 1 30 ns/op 10 MB/s
+BenchmarkTest/name=jack-8 1 20 ns/op 10 MB/s
+`
+
+func TestComputeDeltas(t *testing.T) {
+	base := BenchmarkList(mustParse(deltaBase).Results)
+	candidate := BenchmarkList(mustParse(deltaCandidate).Results)
+	deltas := ComputeDeltas(base, candidate, "ns/op", meanAggregation)
+	require.Equal(t, []Delta{
+		{Name: "BenchmarkTest/name=bob", Baseline: 20, Candidate: 30},
+		{Name: "BenchmarkTest/name=john", Baseline: 20, Candidate: 19},
+	}, deltas)
+	require.Equal(t, 50.0, deltas[0].PercentChange())
+	require.Equal(t, -5.0, deltas[1].PercentChange())
+	require.Empty(t, ComputeDeltas(base, candidate, "B/op", meanAggregation))
+}
+
+func TestComputeDeltas_procs(t *testing.T) {
+	base := BenchmarkList(mustParse(`
+BenchmarkX/size=1 1 100 ns/op
+BenchmarkX/size=1-4 1 100 ns/op
+BenchmarkY-8 1 10 ns/op
+`).Results)
+	candidate := BenchmarkList(mustParse(`
+BenchmarkX/size=1 1 100 ns/op
+BenchmarkX/size=1-4 1 180 ns/op
+BenchmarkY-8 1 15 ns/op
+BenchmarkY-4 1 15 ns/op
+`).Results)
+	// Each procs is its own benchmark, and named with its procs since they differ
+	require.Equal(t, []Delta{
+		{Name: "BenchmarkX/size=1/procs=4", Baseline: 100, Candidate: 180},
+		{Name: "BenchmarkY/procs=8", Baseline: 10, Candidate: 15},
+		{Name: "BenchmarkX/size=1/procs=1", Baseline: 100, Candidate: 100},
+	}, ComputeDeltas(base, candidate, "ns/op", meanAggregation))
+	// The -N suffix is not part of a name, even without tags
+	require.Equal(t, []Delta{
+		{Name: "BenchmarkY", Baseline: 10, Candidate: 15},
+	}, ComputeDeltas(base, candidate[2:3], "ns/op", meanAggregation))
+}
+
+func TestDelta_PercentChange(t *testing.T) {
+	require.Equal(t, 0.0, Delta{}.PercentChange())
+	require.True(t, math.IsInf(Delta{Candidate: 1}.PercentChange(), 1))
+	require.Equal(t, 100.0, Delta{Baseline: -1, Candidate: 0}.PercentChange())
+}
+
+func TestHigherIsBetter(t *testing.T) {
+	require.True(t, HigherIsBetter("MB/s"))
+	require.False(t, HigherIsBetter("ns/op"))
+}
@@ -0,0 +1,129 @@
+package internal
+
+import (
+	"math"
+
+	"github.com/pkg/errors"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// ErrorBars computes how far below and above the drawn value (center) the error bar for all the values of a single
+// x index reaches.  It is never called with an empty list.
+type ErrorBars func(center float64, vals []float64) (low float64, high float64)
+
+var errorBars = map[string]ErrorBars{
+	"stddev": stddevErrorBars,
+	"stderr": stderrErrorBars,
+	"ci95":   ci95ErrorBars,
+	"minmax": minmaxErrorBars,
+}
+
+// ToErrorBars converts a string name to a known way to draw error bars.  Empty or "none" means no error bars and
+// returns nil.
+func ToErrorBars(s string) (ErrorBars, error) {
+	if s == "" || s == "none" {
+		return nil, nil
+	}
+	if eb, exists := errorBars[s]; exists {
+		return eb, nil
+	}
+	return nil, errors.New("unknown error bars " + s)
+}
+
+// stddev is the sample standard deviation.  It is zero for a single value.
+func stddev(vals []float64) float64 {
+	if len(vals) < 2 {
+		return 0
+	}
+	mean := meanAggregation(vals)
+	sum := 0.0
+	for _, v := range vals {
+		sum += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(sum / float64(len(vals)-1))
+}
+
+func stddevErrorBars(_ float64, vals []float64) (float64, float64) {
+	s := stddev(vals)
+	return s, s
+}
+
+func stderrErrorBars(_ float64, vals []float64) (float64, float64) {
+	s := stddev(vals) / math.Sqrt(float64(len(vals)))
+	return s, s
+}
+
+// tCritical95 are the two sided 95% critical values of Student's t distribution, indexed by degrees of freedom.
+var tCritical95 = []float64{
+	0, 12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262, 2.228,
+	2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093, 2.086,
+	2.080, 2.074, 2.069, 2.064, 2.060, 2.056, 2.052, 2.048, 2.045, 2.042,
+}
+
+// ci95ErrorBars is the 95% confidence interval of the mean.  It uses the t distribution since benchmarks are usually
+// run only a handful of times.
+func ci95ErrorBars(_ float64, vals []float64) (float64, float64) {
+	t := 1.96
+	if df := len(vals) - 1; df < len(tCritical95) {
+		t = tCritical95[df]
+	}
+	s := t * stddev(vals) / math.Sqrt(float64(len(vals)))
+	return s, s
+}
+
+func minmaxErrorBars(center float64, vals []float64) (float64, float64) {
+	return center - minAggregation(vals), maxAggregation(vals) - center
+}
+
+// errorPoints are the values and error bars for each x index that has values
+type errorPoints struct {
+	plotter.XYs
+	plotter.YErrors
+}
+
+func makeErrorPoints(f [][]float64, aggregation Aggregation, eb ErrorBars) errorPoints {
+	var ret errorPoints
+	for i, vals := range f {
+		if len(vals) == 0 {
+			continue
+		}
+		center := aggregation(vals)
+		low, high := eb(center, vals)
+		ret.XYs = append(ret.XYs, plotter.XY{
+			X: float64(i),
+			Y: center,
+		})
+		ret.YErrors = append(ret.YErrors, struct{ Low, High float64 }{Low: low, High: high})
+	}
+	return ret
+}
+
+// offsetErrorBars are error bars moved by Offset so they line up with a bar chart's Offset
+type offsetErrorBars struct {
+	*plotter.YErrorBars
+	Offset vg.Length
+}
+
+var _ plot.Plotter = &offsetErrorBars{}
+var _ plot.GlyphBoxer = &offsetErrorBars{}
+
+// Plot draws the error bars moved by Offset
+func (e *offsetErrorBars) Plot(c draw.Canvas, p *plot.Plot) {
+	c.Push()
+	c.Translate(vg.Point{X: e.Offset})
+	e.YErrorBars.Plot(c, p)
+	c.Pop()
+}
+
+// GlyphBoxes returns the error bar's glyph boxes moved by Offset
+func (e *offsetErrorBars) GlyphBoxes(p *plot.Plot) []plot.GlyphBox {
+	ret := e.YErrorBars.GlyphBoxes(p)
+	for i := range ret {
+		ret[i].Rectangle.Min.X += e.Offset
+		ret[i].Rectangle.Max.X += e.Offset
+	}
+	return ret
+}
@@ -0,0 +1,43 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestToErrorBars(t *testing.T) {
+	ebEqual := func(name string, vals []float64, expectedLow float64, expectedHigh float64) func(t *testing.T) {
+		return func(t *testing.T) {
+			eb, err := ToErrorBars(name)
+			require.NoError(t, err)
+			low, high := eb(meanAggregation(vals), vals)
+			require.InDelta(t, expectedLow, low, 0.001)
+			require.InDelta(t, expectedHigh, high, 0.001)
+		}
+	}
+	t.Run("stddev", ebEqual("stddev", []float64{2, 4, 4, 4, 5, 5, 7, 9}, 2.138, 2.138))
+	t.Run("stddevsingle", ebEqual("stddev", []float64{2}, 0, 0))
+	t.Run("stderr", ebEqual("stderr", []float64{1, 3}, 1, 1))
+	t.Run("ci95", ebEqual("ci95", []float64{1, 3}, 12.706, 12.706))
+	t.Run("minmax", ebEqual("minmax", []float64{1, 2, 6}, 2, 3))
+	t.Run("none", func(t *testing.T) {
+		eb, err := ToErrorBars("none")
+		require.NoError(t, err)
+		require.Nil(t, eb)
+	})
+	t.Run("unknown", func(t *testing.T) {
+		_, err := ToErrorBars("variance")
+		require.Error(t, err)
+	})
+}
+
+func TestMakeErrorPoints(t *testing.T) {
+	points := makeErrorPoints([][]float64{{1, 3}, {}, {5}}, meanAggregation, minmaxErrorBars)
+	require.Len(t, points.XYs, 2)
+	require.Equal(t, 2.0, points.XYs[1].X)
+	require.Equal(t, 5.0, points.XYs[1].Y)
+	low, high := points.YError(0)
+	require.Equal(t, 1.0, low)
+	require.Equal(t, 1.0, high)
+}
@@ -0,0 +1,354 @@
+package internal
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/cep21/benchparse"
+	"github.com/pkg/errors"
+)
+
+// Expression is a y unit computed from the other units of a benchmark, like `1e9 / ns/op`.
+type Expression struct {
+	// Name is the unit the computed value is stored as
+	Name string
+	// Source is the expression text
+	Source string
+	root   exprNode
+}
+
+// ToExpression parses `name=expression` or just `expression`, which is then also the unit's name.  Expressions
+// reference units by name and support numbers, + - * /, parentheses and the functions log and abs.  A '/' directly
+// followed by a letter is part of a unit name, so `B/op / allocs/op` needs the spaces around the division.
+func ToExpression(s string) (*Expression, error) {
+	ret := &Expression{
+		Name:   strings.TrimSpace(s),
+		Source: strings.TrimSpace(s),
+	}
+	if kv := strings.SplitN(s, "=", 2); len(kv) == 2 {
+		ret.Name = strings.TrimSpace(kv[0])
+		ret.Source = strings.TrimSpace(kv[1])
+		if ret.Name == "" {
+			return nil, errors.Errorf("expression %s has an empty name", s)
+		}
+	}
+	p := exprParser{s: ret.Source}
+	root, err := p.parse()
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to parse expression %s", ret.Source)
+	}
+	ret.root = root
+	return ret, nil
+}
+
+// Eval computes the expression for a benchmark.  It errors if the benchmark is missing a referenced unit.
+func (e *Expression) Eval(b benchparse.BenchmarkResult) (float64, error) {
+	return e.root.eval(b)
+}
+
+// Units returns every unit the expression references.
+func (e *Expression) Units() []string {
+	var ret OrderedStringSet
+	e.root.units(&ret)
+	return ret.Order
+}
+
+func (e *Expression) String() string {
+	return e.Name + "=" + e.Source
+}
+
+// AddDerivedUnits returns results with each expression's value added as a unit named for the expression.  Results
+// missing a unit an expression references, or whose value is not finite (like a division by zero), do not get that
+// expression's unit, the same as any other missing unit.  It errors if no result can compute an expression, since
+// that is almost always a typo in a unit name, or if a result already has a unit with an expression's name.
+func AddDerivedUnits(log Logger, results []benchparse.BenchmarkResult, exprs []*Expression) ([]benchparse.BenchmarkResult, error) {
+	if len(exprs) == 0 {
+		return results, nil
+	}
+	ret := make([]benchparse.BenchmarkResult, 0, len(results))
+	for _, r := range results {
+		values := make([]benchparse.ValueUnitPair, len(r.Values), len(r.Values)+len(exprs))
+		copy(values, r.Values)
+		r.Values = values
+		ret = append(ret, r)
+	}
+	for _, e := range exprs {
+		var firstErr error
+		computed := 0
+		for i := range ret {
+			if _, exists := ret[i].ValueByUnit(e.Name); exists {
+				return nil, errors.Errorf("expression %s is named %s, which %s already has as a unit", e.Source, e.Name, ret[i].Name)
+			}
+			v, err := e.Eval(ret[i])
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				log.Log(1, "skipping %s of %s: its value %v is not a finite number", e.Name, ret[i].Name, v)
+				continue
+			}
+			ret[i].Values = append(ret[i].Values, benchparse.ValueUnitPair{
+				Value: v,
+				Unit:  e.Name,
+			})
+			computed++
+		}
+		if computed == 0 && firstErr != nil {
+			return nil, errors.Wrapf(firstErr, "no benchmark can compute %s", e.Source)
+		}
+	}
+	return ret, nil
+}
+
+type exprNode interface {
+	eval(b benchparse.BenchmarkResult) (float64, error)
+	units(into *OrderedStringSet)
+}
+
+type numberNode float64
+
+func (n numberNode) eval(benchparse.BenchmarkResult) (float64, error) {
+	return float64(n), nil
+}
+
+func (n numberNode) units(*OrderedStringSet) {}
+
+type unitNode string
+
+func (n unitNode) eval(b benchparse.BenchmarkResult) (float64, error) {
+	v, exists := b.ValueByUnit(string(n))
+	if !exists {
+		return 0, errors.Errorf("benchmark %s has no unit %s", b.Name, string(n))
+	}
+	return v, nil
+}
+
+func (n unitNode) units(into *OrderedStringSet) {
+	into.Add(string(n))
+}
+
+type binaryNode struct {
+	op          byte
+	left, right exprNode
+}
+
+func (n binaryNode) eval(b benchparse.BenchmarkResult) (float64, error) {
+	left, err := n.left.eval(b)
+	if err != nil {
+		return 0, err
+	}
+	right, err := n.right.eval(b)
+	if err != nil {
+		return 0, err
+	}
+	switch n.op {
+	case '+':
+		return left + right, nil
+	case '-':
+		return left - right, nil
+	case '*':
+		return left * right, nil
+	}
+	return left / right, nil
+}
+
+func (n binaryNode) units(into *OrderedStringSet) {
+	n.left.units(into)
+	n.right.units(into)
+}
+
+type funcNode struct {
+	name string
+	f    func(float64) float64
+	arg  exprNode
+}
+
+func (n funcNode) eval(b benchparse.BenchmarkResult) (float64, error) {
+	v, err := n.arg.eval(b)
+	if err != nil {
+		return 0, err
+	}
+	return n.f(v), nil
+}
+
+func (n funcNode) units(into *OrderedStringSet) {
+	n.arg.units(into)
+}
+
+var exprFunctions = map[string]func(float64) float64{
+	"log": math.Log,
+	"abs": math.Abs,
+}
+
+// exprParser is a recursive descent parser of
+//
+//	expr    = term (('+' | '-') term)*
+//	term    = unary (('*' | '/') unary)*
+//	unary   = '-' unary | primary
+//	primary = number | unit | function '(' expr ')' | '(' expr ')'
+type exprParser struct {
+	s   string
+	pos int
+}
+
+func (p *exprParser) parse() (exprNode, error) {
+	n, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek() != 0 {
+		return nil, errors.Errorf("unexpected %q at position %d", p.s[p.pos:], p.pos)
+	}
+	return n, nil
+}
+
+// peek returns the next non space byte, or 0 at the end of the expression
+func (p *exprParser) peek() byte {
+	for p.pos < len(p.s) && p.s[p.pos] == ' ' {
+		p.pos++
+	}
+	if p.pos == len(p.s) {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+func (p *exprParser) expr() (exprNode, error) {
+	left, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for op := p.peek(); op == '+' || op == '-'; op = p.peek() {
+		p.pos++
+		right, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) term() (exprNode, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for op := p.peek(); op == '*' || op == '/'; op = p.peek() {
+		p.pos++
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) unary() (exprNode, error) {
+	if p.peek() == '-' {
+		p.pos++
+		n, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return binaryNode{op: '-', left: numberNode(0), right: n}, nil
+	}
+	return p.primary()
+}
+
+func (p *exprParser) primary() (exprNode, error) {
+	c := p.peek()
+	switch {
+	case c == 0:
+		return nil, errors.New("unexpected end of expression")
+	case c == '(':
+		p.pos++
+		n, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ')' {
+			return nil, errors.Errorf("missing ) at position %d", p.pos)
+		}
+		p.pos++
+		return n, nil
+	case (c >= '0' && c <= '9') || c == '.':
+		return p.number()
+	}
+	start := p.pos
+	name := p.unit()
+	if name == "" {
+		return nil, errors.Errorf("unexpected %q at position %d", p.s[start:], start)
+	}
+	if f, exists := exprFunctions[name]; exists && p.peek() == '(' {
+		p.pos++
+		arg, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ')' {
+			return nil, errors.Errorf("missing ) after argument of %s at position %d", name, p.pos)
+		}
+		p.pos++
+		return funcNode{name: name, f: f, arg: arg}, nil
+	}
+	return unitNode(name), nil
+}
+
+func (p *exprParser) number() (exprNode, error) {
+	start := p.pos
+	for p.pos < len(p.s) && (isDigit(p.s[p.pos]) || p.s[p.pos] == '.') {
+		p.pos++
+	}
+	if p.pos < len(p.s) && (p.s[p.pos] == 'e' || p.s[p.pos] == 'E') {
+		p.pos++
+		if p.pos < len(p.s) && (p.s[p.pos] == '+' || p.s[p.pos] == '-') {
+			p.pos++
+		}
+		for p.pos < len(p.s) && isDigit(p.s[p.pos]) {
+			p.pos++
+		}
+	}
+	v, err := strconv.ParseFloat(p.s[start:p.pos], 64)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid number at position %d", start)
+	}
+	return numberNode(v), nil
+}
+
+// unit reads a unit name.  A unit starts with a letter, % or _ and ends at a space, an operator, a parenthesis or a
+// '/' that is not followed by a letter.
+func (p *exprParser) unit() string {
+	start := p.pos
+	for p.pos < len(p.s) {
+		r, size := utf8.DecodeRuneInString(p.s[p.pos:])
+		if p.pos == start && !isUnitStart(r) {
+			break
+		}
+		if r == '/' {
+			next, _ := utf8.DecodeRuneInString(p.s[p.pos+size:])
+			if !isUnitStart(next) {
+				break
+			}
+		} else if r == ' ' || strings.ContainsRune("+-*()", r) {
+			break
+		}
+		p.pos += size
+	}
+	return p.s[start:p.pos]
+}
+
+func isUnitStart(r rune) bool {
+	return unicode.IsLetter(r) || r == '%' || r == '_'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
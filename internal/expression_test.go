@@ -0,0 +1,103 @@
+package internal
+
+import (
+	"bytes"
+	"log"
+	"math"
+	"testing"
+
+	"github.com/cep21/benchparse"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToExpression(t *testing.T) {
+	b := benchparse.BenchmarkResult{
+		Name: "BenchmarkDecode",
+		Values: []benchparse.ValueUnitPair{
+			{Value: 2000, Unit: "ns/op"},
+			{Value: 64, Unit: "B/op"},
+			{Value: 4, Unit: "allocs/op"},
+			{Value: 50, Unit: "%correct"},
+		},
+	}
+	evalEqual := func(s string, expected float64) func(t *testing.T) {
+		return func(t *testing.T) {
+			e, err := ToExpression(s)
+			require.NoError(t, err)
+			v, err := e.Eval(b)
+			require.NoError(t, err)
+			require.InDelta(t, expected, v, 1e-9)
+		}
+	}
+	t.Run("divide", evalEqual("ns/op / 1e6", 0.002))
+	t.Run("units", evalEqual("B/op / allocs/op", 16))
+	t.Run("opspersec", evalEqual("1e9 / ns/op", 500000))
+	t.Run("nospace", evalEqual("ns/op/1e3", 2))
+	t.Run("precedence", evalEqual("1 + 2 * 3 - 4", 3))
+	t.Run("parens", evalEqual("(1 + 2) * 3", 9))
+	t.Run("unary", evalEqual("-allocs/op + 5", 1))
+	t.Run("percent", evalEqual("%correct * 2", 100))
+	t.Run("abs", evalEqual("abs(1 - ns/op)", 1999))
+	t.Run("log", evalEqual("log(B/op)", math.Log(64)))
+	t.Run("named", func(t *testing.T) {
+		e, err := ToExpression("ops/s=1e9 / ns/op")
+		require.NoError(t, err)
+		require.Equal(t, "ops/s", e.Name)
+		require.Equal(t, "1e9 / ns/op", e.Source)
+		require.Equal(t, []string{"ns/op"}, e.Units())
+	})
+	t.Run("missing", func(t *testing.T) {
+		e, err := ToExpression("MB/s * 2")
+		require.NoError(t, err)
+		_, err = e.Eval(b)
+		require.EqualError(t, err, "benchmark BenchmarkDecode has no unit MB/s")
+	})
+	for _, bad := range []string{"", "1 +", "(1 + 2", "abs(1", "1 2", "ns/op )", "=1", "1e", "*"} {
+		bad := bad
+		t.Run("bad:"+bad, func(t *testing.T) {
+			_, err := ToExpression(bad)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestAddDerivedUnits(t *testing.T) {
+	in := []benchparse.BenchmarkResult{
+		{Name: "BenchmarkA", Values: []benchparse.ValueUnitPair{{Value: 10, Unit: "B/op"}, {Value: 2, Unit: "allocs/op"}}},
+		{Name: "BenchmarkB", Values: []benchparse.ValueUnitPair{{Value: 10, Unit: "ns/op"}}},
+	}
+	e, err := ToExpression("B/alloc=B/op / allocs/op")
+	require.NoError(t, err)
+	out, err := AddDerivedUnits(Logger{}, in, []*Expression{e})
+	require.NoError(t, err)
+	v, exists := out[0].ValueByUnit("B/alloc")
+	require.True(t, exists)
+	require.Equal(t, 5.0, v)
+	_, exists = out[1].ValueByUnit("B/alloc")
+	require.False(t, exists)
+	// The input is not changed
+	require.Len(t, in[0].Values, 2)
+
+	// Dividing by zero allocs/op does not add B/alloc, and is logged
+	var buf bytes.Buffer
+	in[1].Values = []benchparse.ValueUnitPair{{Value: 10, Unit: "B/op"}, {Value: 0, Unit: "allocs/op"}}
+	out, err = AddDerivedUnits(Logger{Verbosity: 1, Logger: log.New(&buf, "", 0)}, in, []*Expression{e})
+	require.NoError(t, err)
+	_, exists = out[1].ValueByUnit("B/alloc")
+	require.False(t, exists)
+	require.Contains(t, buf.String(), "skipping B/alloc of BenchmarkB")
+
+	typo, err := ToExpression("B/opp / allocs/op")
+	require.NoError(t, err)
+	_, err = AddDerivedUnits(Logger{}, in, []*Expression{typo})
+	require.Error(t, err)
+
+	// An expression cannot replace a unit the benchmark already has
+	doubled, err := ToExpression("B/op=B/op * 2")
+	require.NoError(t, err)
+	_, err = AddDerivedUnits(Logger{}, in, []*Expression{doubled})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "already has as a unit")
+	_, err = AddDerivedUnits(Logger{}, in, []*Expression{e, e})
+	require.Error(t, err)
+}
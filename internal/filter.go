@@ -1,6 +1,8 @@
 package internal
 
 import (
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/cep21/benchparse"
@@ -10,50 +12,150 @@ import (
 type Filter struct {
 }
 
+// FilterOp is how a FilterPair compares its Value to a benchmark's value
+type FilterOp int
+
+const (
+	// FilterOpEqual matches a benchmark value equal to any of the | separated alternatives in Value.  An empty Value
+	// matches any benchmark with the key.
+	FilterOpEqual FilterOp = iota
+	// FilterOpRegex matches a benchmark value that matches Value as a regular expression
+	FilterOpRegex
+)
+
 // FilterPair controls how to filter.  It means filter only key=value.  If value is empty, then filters for existence.
 type FilterPair struct {
 	Key   string
 	Value string
+	// Op is how Value is compared
+	Op FilterOp
+	// Negate matches only the benchmarks the rest of this FilterPair would not match
+	Negate bool
+	regex  *regexp.Regexp
+}
+
+// FilterParseError is returned when a segment of a filter cannot be understood
+type FilterParseError struct {
+	// Segment is the part of the filter, between / characters, that is invalid
+	Segment string
+	// Reason explains why Segment is invalid
+	Reason string
+}
+
+func (f *FilterParseError) Error() string {
+	return "invalid filter " + strconv.Quote(f.Segment) + ": " + f.Reason
+}
+
+// Matches returns true if the benchmark key/value pairs in values pass this filter
+func (f *FilterPair) Matches(values map[string]string) bool {
+	val, exists := values[f.Key]
+	matched := exists && f.matchesValue(val)
+	return matched != f.Negate
+}
+
+func (f *FilterPair) matchesValue(val string) bool {
+	if f.Op == FilterOpRegex {
+		return f.regex.MatchString(val)
+	}
+	if f.Value == "" {
+		return true
+	}
+	for _, alt := range strings.Split(f.Value, "|") {
+		if alt == val {
+			return true
+		}
+	}
+	return false
 }
 
 // ToFilterPairs converts a string of benchmark format to a list of filter pairs.  For example, BenchmarkBob/name=john
-// would become [{Key: BenchmarkBob}, {Key: name, Value: bob}]
-func ToFilterPairs(s string) []FilterPair {
-	parts := strings.Split(s, "/")
+// would become [{Key: BenchmarkBob}, {Key: name, Value: bob}].  Besides key=value and key, each segment can be
+// key=a|b to match any alternative, key=~regex to match a regular expression, and key!=value or !segment to match only
+// what the segment would not.  A value that contains / (like pkg=github.com/cep21/benchdraw) can escape it as \/.
+func ToFilterPairs(s string) ([]FilterPair, error) {
+	parts := splitFilter(s)
 	ret := make([]FilterPair, 0, len(parts))
 	for _, p := range parts {
 		if len(p) == 0 {
 			continue
 		}
-		kv := strings.SplitN(p, "=", 2)
-		if len(kv) == 1 {
-			ret = append(ret, FilterPair{
-				Key: p,
-			})
-		} else {
-			ret = append(ret, FilterPair{
-				Key:   kv[0],
-				Value: kv[1],
-			})
+		fp, err := toFilterPair(p)
+		if err != nil {
+			return nil, err
 		}
+		ret = append(ret, fp)
 	}
-	return ret
+	return ret, nil
+}
+
+// splitFilter splits s by /, except for escaped \/
+func splitFilter(s string) []string {
+	var ret []string
+	var current strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && s[i+1] == '/' {
+			mustNotError(current.WriteByte('/'))
+			i++
+			continue
+		}
+		if s[i] == '/' {
+			ret = append(ret, current.String())
+			current.Reset()
+			continue
+		}
+		mustNotError(current.WriteByte(s[i]))
+	}
+	return append(ret, current.String())
+}
+
+func toFilterPair(p string) (FilterPair, error) {
+	var ret FilterPair
+	segment := p
+	if strings.HasPrefix(segment, "!") {
+		ret.Negate = true
+		segment = segment[1:]
+	}
+	kv := strings.SplitN(segment, "=", 2)
+	if len(kv) == 1 {
+		ret.Key = segment
+	} else {
+		ret.Key, ret.Value = kv[0], kv[1]
+		if strings.HasSuffix(ret.Key, "!") {
+			if ret.Negate {
+				return ret, &FilterParseError{Segment: p, Reason: "cannot negate twice"}
+			}
+			ret.Negate = true
+			ret.Key = strings.TrimSuffix(ret.Key, "!")
+		}
+		if strings.HasPrefix(ret.Value, "~") {
+			ret.Op = FilterOpRegex
+			ret.Value = ret.Value[1:]
+			r, err := regexp.Compile(ret.Value)
+			if err != nil {
+				return ret, &FilterParseError{Segment: p, Reason: err.Error()}
+			}
+			ret.regex = r
+		}
+	}
+	if ret.Key == "" {
+		return ret, &FilterParseError{Segment: p, Reason: "empty key"}
+	}
+	return ret, nil
 }
 
-// FilterBenchmarks only returns benchmarks that contain this unit and belong to the filter pairs.
-func (f *Filter) FilterBenchmarks(in []benchparse.BenchmarkResult, filters []FilterPair, unit string) BenchmarkList {
+// FilterBenchmarks only returns benchmarks that contain at least one of these units and belong to the filter pairs.
+func (f *Filter) FilterBenchmarks(in []benchparse.BenchmarkResult, filters []FilterPair, units ...string) BenchmarkList {
 	ret := make([]benchparse.BenchmarkResult, 0, len(in))
 	for _, b := range in {
 		// Benchmark must have a valid unit
-		if _, exists := b.ValueByUnit(unit); !exists {
+		if !hasAnyUnit(b, units) {
 			continue
 		}
-		keys := b.AllKeyValuePairs()
+		keys := makeKeys(b)
 		okToAdd := true
 		// each filter must pass
 		for _, f := range filters {
-			val, exists := keys.Contents[f.Key]
-			if !exists || (f.Value != "" && f.Value != val) {
+			if !f.Matches(keys.Values) {
 				okToAdd = false
 				break
 			}
@@ -64,3 +166,12 @@ func (f *Filter) FilterBenchmarks(in []benchparse.BenchmarkResult, filters []Fil
 	}
 	return ret
 }
+
+func hasAnyUnit(b benchparse.BenchmarkResult, units []string) bool {
+	for _, unit := range units {
+		if _, exists := b.ValueByUnit(unit); exists {
+			return true
+		}
+	}
+	return false
+}
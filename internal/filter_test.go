@@ -22,7 +22,9 @@ func makePairs(args ...string) []FilterPair {
 func TestToFilterPairs(t *testing.T) {
 	filtersEqual := func(arg string, want []FilterPair) func(t *testing.T) {
 		return func(t *testing.T) {
-			require.Equal(t, want, ToFilterPairs(arg))
+			got, err := ToFilterPairs(arg)
+			require.NoError(t, err)
+			require.Equal(t, want, got)
 		}
 	}
 	t.Run("empty", filtersEqual("", makePairs()))
@@ -30,6 +32,45 @@ func TestToFilterPairs(t *testing.T) {
 	t.Run("set", filtersEqual("key=value", makePairs("key", "value")))
 	t.Run("settwo", filtersEqual("key=value/key2=value2", makePairs("key", "value", "key2", "value2")))
 	t.Run("setwithempty", filtersEqual("key=value/", makePairs("key", "value")))
+	t.Run("escapedslash", filtersEqual(`pkg=github.com\/cep21/key`, makePairs("pkg", "github.com/cep21", "key", "")))
+	t.Run("alternatives", filtersEqual("key=a|b", makePairs("key", "a|b")))
+	t.Run("negate", filtersEqual("!key=value", []FilterPair{{Key: "key", Value: "value", Negate: true}}))
+	t.Run("notequal", filtersEqual("key!=value", []FilterPair{{Key: "key", Value: "value", Negate: true}}))
+	t.Run("notexists", filtersEqual("!key", []FilterPair{{Key: "key", Negate: true}}))
+	mustErr := func(arg string) func(t *testing.T) {
+		return func(t *testing.T) {
+			_, err := ToFilterPairs(arg)
+			require.Error(t, err)
+			require.IsType(t, &FilterParseError{}, err)
+		}
+	}
+	t.Run("emptykey", mustErr("=value"))
+	t.Run("justnegate", mustErr("!"))
+	t.Run("doublenegate", mustErr("!key!=value"))
+	t.Run("badregex", mustErr("key=~("))
+}
+
+func TestFilterPair_Matches(t *testing.T) {
+	values := map[string]string{"digest": "segmentio", "size": "1e5"}
+	matches := func(arg string, expected bool) func(t *testing.T) {
+		return func(t *testing.T) {
+			pairs, err := ToFilterPairs(arg)
+			require.NoError(t, err)
+			require.Len(t, pairs, 1)
+			require.Equal(t, expected, pairs[0].Matches(values))
+		}
+	}
+	t.Run("exists", matches("digest", true))
+	t.Run("notexists", matches("source", false))
+	t.Run("negatenotexists", matches("!source", true))
+	t.Run("equal", matches("digest=segmentio", true))
+	t.Run("notequal", matches("digest!=segmentio", false))
+	t.Run("negateequal", matches("!digest=caio", true))
+	t.Run("alternative", matches("size=1e4|1e5", true))
+	t.Run("noalternative", matches("size=1e4|1e6", false))
+	t.Run("regex", matches("digest=~^seg", true))
+	t.Run("noregex", matches("digest=~^cai", false))
+	t.Run("negateregex", matches("digest!=~^seg", false))
 }
 
 func sameLists(t *testing.T, expected []benchparse.BenchmarkResult, seen []benchparse.BenchmarkResult) {
@@ -46,7 +87,9 @@ func TestFilter_FilterBenchmarks(t *testing.T) {
 		return func(t *testing.T) {
 			bl := BenchmarkList(mustParse(data).Results)
 			f := Filter{}
-			got := f.FilterBenchmarks(bl, ToFilterPairs(filter), unit)
+			pairs, err := ToFilterPairs(filter)
+			require.NoError(t, err)
+			got := f.FilterBenchmarks(bl, pairs, unit)
 			sameLists(t, expected, got)
 		}
 	}
@@ -74,6 +117,21 @@ BenchmarkTdigest_TotalSize/digest=caio-8         	     100	  10687130 ns/op	   1
 BenchmarkTdigest_Add/source=linear/digest=caio-8 	 1299776	       941 ns/op	      33 B/op	       0 allocs/op
 BenchmarkTdigest_Add/source=rand/digest=caio-8                	 4080662	       317 ns/op	       0 B/op	       0 allocs/op
 `).Results))
+	t.Run("negatefilter", filterList(run1, "BenchmarkTdigest_Add/digest!=caio/source=rand|linear", "ns/op", mustParse(`
+goos: linux
+goarch: amd64
+pkg: github.com/cep21/tdigestbench
+BenchmarkTdigest_Add/source=linear/digest=segmentio-8         	 1000000	      5602 ns/op	       8 B/op	       1 allocs/op
+BenchmarkTdigest_Add/source=rand/digest=segmentio-8           	 2220681	       785 ns/op	       8 B/op	       1 allocs/op
+`).Results))
+	t.Run("configfilter", filterList(run1, `BenchmarkTdigest_TotalSize/goos=linux/pkg=github.com\/cep21\/tdigestbench`, "ns/op", mustParse(`
+goos: linux
+goarch: amd64
+pkg: github.com/cep21/tdigestbench
+BenchmarkTdigest_TotalSize/digest=caio-8         	     100	  10687130 ns/op	   17920 B/op	      11 allocs/op
+BenchmarkTdigest_TotalSize/digest=segmentio-8    	     422	   2844918 ns/op	 1380108 B/op	   54777 allocs/op
+`).Results))
+	t.Run("configfiltermiss", filterList(run1, "goos=darwin", "ns/op", []benchparse.BenchmarkResult{}))
 	t.Run("unitonone", filterList(run4, "BenchmarkTest", "allocs/op", mustParse(`
 BenchmarkTest/name=bob/type=digest 1 10 ns/op 5 allocs/op
 `).Results))
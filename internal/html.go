@@ -0,0 +1,446 @@
+package internal
+
+import (
+	"html/template"
+	"io"
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+// HTMLPlotter writes a single, offline HTML page that draws plots with embedded JavaScript.  Hovering a value shows
+// its exact value, sample count and spread, clicking the legend hides lines and dragging zooms the x axis.
+type HTMLPlotter struct {
+}
+
+// htmlChart is the data embedded in the page as JSON
+type htmlChart struct {
+	Title    string      `json:"title"`
+	X        string      `json:"x"`
+	Plot     string      `json:"plot"`
+	XValues  []string    `json:"xValues"`
+	XPos     []float64   `json:"xPos"`
+	NumericX bool        `json:"numericX"`
+	LogX     bool        `json:"logX"`
+	LogY     bool        `json:"logY"`
+	Panels   []htmlPanel `json:"panels"`
+	Deltas   []htmlDelta `json:"deltas,omitempty"`
+}
+
+type htmlPanel struct {
+	Y     string     `json:"y"`
+	Lines []htmlLine `json:"lines"`
+}
+
+type htmlLine struct {
+	Name string `json:"name"`
+	// Points has an entry for each x value, which is nil if the line has no values at that x
+	Points []*htmlPoint `json:"points"`
+}
+
+type htmlPoint struct {
+	Value  float64 `json:"value"`
+	Count  int     `json:"count"`
+	Min    float64 `json:"min"`
+	Q1     float64 `json:"q1"`
+	Median float64 `json:"median"`
+	Q3     float64 `json:"q3"`
+	Max    float64 `json:"max"`
+	Stddev float64 `json:"stddev"`
+	// Low and High are where the error bars, if any, end
+	Low  *float64 `json:"low,omitempty"`
+	High *float64 `json:"high,omitempty"`
+}
+
+type htmlDelta struct {
+	Name      string  `json:"name"`
+	Baseline  float64 `json:"baseline"`
+	Candidate float64 `json:"candidate"`
+	Change    float64 `json:"change"`
+	Improved  bool    `json:"improved"`
+}
+
+var htmlPlotTypes = map[PlotType]string{
+	PlotTypeBar:   "bar",
+	PlotTypeLine:  "line",
+	PlotTypeBox:   "box",
+	PlotTypeDelta: "delta",
+}
+
+// Render will write to out an HTML page drawing every panel
+func (h *HTMLPlotter) Render(log Logger, out io.Writer, chart Chart) error {
+	if chart.Type == PlotTypeDelta {
+		return h.renderDelta(out, chart)
+	}
+	scale, uniqueKeys := chart.Scale, chart.XValues
+	pm, err := makePlacement(AxisScale{NumericX: scale.NumericX, LogX: scale.LogX}, nil, uniqueKeys.Order)
+	if err != nil {
+		return errors.Wrap(err, "unable to place x values")
+	}
+	hc := htmlChart{
+		Title:    chart.Title,
+		X:        chart.X,
+		Plot:     htmlPlotTypes[chart.Type],
+		XValues:  uniqueKeys.Order,
+		NumericX: scale.NumericX || scale.LogX,
+		LogX:     scale.LogX,
+		LogY:     scale.LogY,
+	}
+	for i := range uniqueKeys.Order {
+		hc.XPos = append(hc.XPos, pm.x(i))
+	}
+	for _, panel := range chart.ReadablePanels() {
+		hp := htmlPanel{Y: panel.Y}
+		for _, line := range panel.Lines {
+			hl := htmlLine{Name: line.Name}
+			for _, vals := range line.Values {
+				hl.Points = append(hl.Points, makeHTMLPoint(vals, chart.Aggregation, chart.ErrorBars))
+			}
+			hp.Lines = append(hp.Lines, hl)
+		}
+		hc.Panels = append(hc.Panels, hp)
+	}
+	log.Log(2, "html chart: %v", hc)
+	return h.write(out, hc)
+}
+
+// renderDelta will write to out an HTML page with a horizontal bar for each delta's percent change
+func (h *HTMLPlotter) renderDelta(out io.Writer, chart Chart) error {
+	hc := htmlChart{
+		Title: chart.Title,
+		Plot:  htmlPlotTypes[PlotTypeDelta],
+		Panels: []htmlPanel{
+			{Y: "% change in " + chart.Unit},
+		},
+	}
+	for _, d := range chart.Deltas {
+		change := d.PercentChange()
+		hc.Deltas = append(hc.Deltas, htmlDelta{
+			Name:      d.Name,
+			Baseline:  d.Baseline,
+			Candidate: d.Candidate,
+			Change:    jsonFinite(change),
+			Improved:  change == 0 || (change > 0) == HigherIsBetter(chart.Unit),
+		})
+	}
+	return h.write(out, hc)
+}
+
+func makeHTMLPoint(vals []float64, agg Aggregation, eb ErrorBars) *htmlPoint {
+	if len(vals) == 0 {
+		return nil
+	}
+	ret := &htmlPoint{
+		Value:  agg(vals),
+		Count:  len(vals),
+		Min:    minAggregation(vals),
+		Q1:     percentileAggregation(25)(vals),
+		Median: medianAggregation(vals),
+		Q3:     percentileAggregation(75)(vals),
+		Max:    maxAggregation(vals),
+		Stddev: stddev(vals),
+	}
+	if eb != nil {
+		// Error bars are distances from the value, but the page wants where they end
+		below, above := eb(ret.Value, vals)
+		low, high := jsonFinite(ret.Value-below), jsonFinite(ret.Value+above)
+		ret.Low, ret.High = &low, &high
+	}
+	for _, v := range []*float64{&ret.Value, &ret.Min, &ret.Q1, &ret.Median, &ret.Q3, &ret.Max, &ret.Stddev} {
+		*v = jsonFinite(*v)
+	}
+	return ret
+}
+
+// jsonFinite clamps v to a number JSON can hold
+func jsonFinite(v float64) float64 {
+	if math.IsNaN(v) {
+		return 0
+	}
+	return math.Max(math.Min(v, math.MaxFloat64), -math.MaxFloat64)
+}
+
+func (h *HTMLPlotter) write(out io.Writer, chart htmlChart) error {
+	if err := htmlTemplate.Execute(out, chart); err != nil {
+		return errors.Wrap(err, "unable to write html")
+	}
+	return nil
+}
+
+var htmlTemplate = template.Must(template.New("chart").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 20px; color: #222; }
+h1 { font-size: 18px; text-align: center; }
+.legend { text-align: center; margin: 8px; }
+.legend span { cursor: pointer; margin: 0 10px; user-select: none; }
+.legend span.hidden { opacity: 0.3; }
+.legend i { display: inline-block; width: 12px; height: 12px; margin-right: 4px; vertical-align: middle; }
+.panel { position: relative; }
+svg { display: block; width: 100%; }
+svg text { font-size: 12px; }
+.axis { stroke: #222; }
+.grid { stroke: #ddd; }
+.zoom { fill: rgba(0, 0, 255, 0.1); }
+#tooltip { position: absolute; pointer-events: none; background: #fff; border: 1px solid #888; padding: 6px;
+  font-size: 12px; white-space: pre; display: none; }
+.hint { text-align: center; font-size: 11px; color: #888; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<div class="legend" id="legend"></div>
+<div id="panels"></div>
+<div class="hint">Hover for values.  Click the legend to hide lines.  Drag to zoom and double click to reset.</div>
+<div id="tooltip"></div>
+<script>
+var chart = {{.}};
+(function() {
+  "use strict";
+  var colors = ["#f0535f", "#7bc46b", "#4a8ad3", "#b0a400", "#b55fc0", "#00b3b3", "#e27a35", "#808080"];
+  var hidden = {};
+  var zoom = null;
+  var tooltip = document.getElementById("tooltip");
+  var svgNS = "http://www.w3.org/2000/svg";
+  var margin = {left: 70, right: 20, top: 10, bottom: 40};
+
+  function el(name, attrs, parent) {
+    var e = document.createElementNS(svgNS, name);
+    for (var k in attrs) { e.setAttribute(k, attrs[k]); }
+    if (parent) { parent.appendChild(e); }
+    return e;
+  }
+  function fmt(v) { return Number(v.toPrecision(4)).toString(); }
+  function ticks(lo, hi, log) {
+    var ret = [];
+    if (log) {
+      for (var p = Math.floor(Math.log10(lo)); p <= Math.ceil(Math.log10(hi)); p++) { ret.push(Math.pow(10, p)); }
+      return ret;
+    }
+    var step = Math.pow(10, Math.floor(Math.log10((hi - lo) / 5 || 1)));
+    if ((hi - lo) / step > 20) { step *= 5; } else if ((hi - lo) / step > 10) { step *= 2; }
+    for (var t = Math.ceil(lo / step) * step; t <= hi + step / 1e6; t += step) { ret.push(t); }
+    return ret;
+  }
+  function scale(lo, hi, a, b, log) {
+    if (log) { lo = Math.log10(lo); hi = Math.log10(hi); }
+    return function(v) {
+      if (log) { v = Math.log10(v); }
+      return hi === lo ? (a + b) / 2 : a + (v - lo) / (hi - lo) * (b - a);
+    };
+  }
+  function show(evt, text) {
+    tooltip.textContent = text;
+    tooltip.style.display = "block";
+    tooltip.style.left = (evt.pageX + 12) + "px";
+    tooltip.style.top = (evt.pageY + 12) + "px";
+  }
+  function hide() { tooltip.style.display = "none"; }
+  function hover(e, text) {
+    e.addEventListener("mousemove", function(evt) { show(evt, text); });
+    e.addEventListener("mouseout", hide);
+  }
+  function describe(line, i, p) {
+    var lines = [];
+    if (line.name) { lines.push(line.name); }
+    lines.push((chart.x ? chart.x + "=" : "") + chart.xValues[i]);
+    lines.push("value: " + fmt(p.value));
+    lines.push("samples: " + p.count);
+    lines.push("min " + fmt(p.min) + " / median " + fmt(p.median) + " / max " + fmt(p.max));
+    lines.push("stddev: " + fmt(p.stddev));
+    if (p.low !== undefined) { lines.push("error bars: " + fmt(p.low) + " to " + fmt(p.high)); }
+    return lines.join("\n");
+  }
+
+  function drawLegend() {
+    var legend = document.getElementById("legend");
+    legend.innerHTML = "";
+    if (chart.plot === "delta") { return; }
+    chart.panels[0].lines.forEach(function(line, i) {
+      if (!line.name) { return; }
+      var s = document.createElement("span");
+      s.className = hidden[i] ? "hidden" : "";
+      var swatch = document.createElement("i");
+      swatch.style.background = colors[i % colors.length];
+      s.appendChild(swatch);
+      s.appendChild(document.createTextNode(line.name));
+      s.addEventListener("click", function() { hidden[i] = !hidden[i]; render(); });
+      legend.appendChild(s);
+    });
+  }
+
+  // xRange is the visible range of x positions.  Nominal x values are at their index.
+  function xRange() {
+    var pos = chart.numericX ? chart.xPos : chart.xValues.map(function(_, i) { return i; });
+    var lo = Math.min.apply(null, pos), hi = Math.max.apply(null, pos);
+    if (!chart.numericX) { lo -= 0.5; hi += 0.5; }
+    if (zoom) { return zoom; }
+    return [lo, hi];
+  }
+  function xOf(i) { return chart.numericX ? chart.xPos[i] : i; }
+
+  function drawPanel(panel, parent, last) {
+    var width = parent.clientWidth || 800;
+    var height = 320;
+    var svg = el("svg", {viewBox: "0 0 " + width + " " + height, height: height}, parent);
+    var xr = xRange();
+    var sx = scale(xr[0], xr[1], margin.left, width - margin.right, chart.logX);
+    var inView = function(i) { var v = xOf(i); return v >= xr[0] && v <= xr[1]; };
+    var lo = Infinity, hi = -Infinity;
+    panel.lines.forEach(function(line, li) {
+      if (hidden[li]) { return; }
+      line.points.forEach(function(p, i) {
+        if (!p || !inView(i)) { return; }
+        var vals = chart.plot === "box" ? [p.min, p.max] : [p.value];
+        if (p.low !== undefined) { vals.push(p.low, p.high); }
+        vals.forEach(function(v) { lo = Math.min(lo, v); hi = Math.max(hi, v); });
+      });
+    });
+    if (lo === Infinity) { lo = chart.logY ? 1 : 0; hi = lo + 1; }
+    if (chart.plot === "bar" && !chart.logY) { lo = Math.min(lo, 0); hi = Math.max(hi, 0); }
+    if (!chart.logY) { var pad = (hi - lo) * 0.05 || 1; hi += pad; if (lo < 0 || chart.plot !== "bar") { lo -= pad; } }
+    var sy = scale(lo, hi, height - margin.bottom, margin.top, chart.logY);
+    var clip = "clip" + Math.random().toString(36).slice(2);
+    el("rect", {x: margin.left, y: 0, width: width - margin.left - margin.right, height: height}, el("clipPath", {id: clip}, el("defs", {}, svg)));
+    ticks(lo, hi, chart.logY).forEach(function(t) {
+      if (t < lo || t > hi) { return; }
+      el("line", {"class": "grid", x1: margin.left, x2: width - margin.right, y1: sy(t), y2: sy(t)}, svg);
+      el("text", {x: margin.left - 6, y: sy(t) + 4, "text-anchor": "end"}, svg).textContent = fmt(t);
+    });
+    var label = el("text", {x: 14, y: (height - margin.bottom) / 2, "text-anchor": "middle",
+      transform: "rotate(-90 14 " + (height - margin.bottom) / 2 + ")"}, svg);
+    label.textContent = panel.y;
+    el("line", {"class": "axis", x1: margin.left, x2: margin.left, y1: margin.top, y2: height - margin.bottom}, svg);
+    el("line", {"class": "axis", x1: margin.left, x2: width - margin.right, y1: height - margin.bottom, y2: height - margin.bottom}, svg);
+    if (chart.numericX) {
+      ticks(xr[0], xr[1], chart.logX).forEach(function(t) {
+        if (t < xr[0] || t > xr[1]) { return; }
+        el("text", {x: sx(t), y: height - margin.bottom + 16, "text-anchor": "middle"}, svg).textContent = fmt(t);
+      });
+    } else {
+      chart.xValues.forEach(function(v, i) {
+        if (!inView(i)) { return; }
+        el("text", {x: sx(i), y: height - margin.bottom + 16, "text-anchor": "middle"}, svg).textContent = v;
+      });
+    }
+    if (last && chart.x) {
+      el("text", {x: (margin.left + width - margin.right) / 2, y: height - 6, "text-anchor": "middle"}, svg).textContent = chart.x;
+    }
+    var g = el("g", {"clip-path": "url(#" + clip + ")"}, svg);
+    var visible = panel.lines.map(function(_, i) { return i; }).filter(function(i) { return !hidden[i]; });
+    // Bars and boxes for an x value share a slot as wide as the closest two x values
+    var slot = (width - margin.left - margin.right) / Math.max(chart.xValues.length, 1) * 0.8;
+    if (chart.numericX && chart.xValues.length > 1) {
+      var closest = Infinity;
+      for (var i = 1; i < chart.xPos.length; i++) { closest = Math.min(closest, Math.abs(sx(chart.xPos[i]) - sx(chart.xPos[i - 1]))); }
+      slot = Math.min(slot, closest * 0.8);
+    }
+    var each = slot / Math.max(visible.length, 1);
+    var base = chart.logY ? lo : 0;
+    visible.forEach(function(li, vi) {
+      var line = panel.lines[li];
+      var color = colors[li % colors.length];
+      var offset = -slot / 2 + each * vi;
+      if (chart.plot === "line") {
+        var pts = [];
+        line.points.forEach(function(p, i) { if (p) { pts.push(sx(xOf(i)) + "," + sy(p.value)); } });
+        el("polyline", {points: pts.join(" "), fill: "none", stroke: color, "stroke-width": 2}, g);
+      }
+      line.points.forEach(function(p, i) {
+        if (!p) { return; }
+        var cx = sx(xOf(i));
+        var shape;
+        if (chart.plot === "line") {
+          shape = el("circle", {cx: cx, cy: sy(p.value), r: 4, fill: color}, g);
+        } else if (chart.plot === "box") {
+          var bx = cx + offset + each * 0.1, bw = each * 0.8;
+          el("line", {x1: bx + bw / 2, x2: bx + bw / 2, y1: sy(p.min), y2: sy(p.max), stroke: color}, g);
+          shape = el("rect", {x: bx, width: bw, y: sy(p.q3), height: Math.max(sy(p.q1) - sy(p.q3), 1), fill: color, "fill-opacity": 0.5, stroke: color}, g);
+          el("line", {x1: bx, x2: bx + bw, y1: sy(p.median), y2: sy(p.median), stroke: "#222", "stroke-width": 2}, g);
+        } else {
+          var top = sy(Math.max(p.value, base)), bottom = sy(Math.min(p.value, base));
+          shape = el("rect", {x: cx + offset, width: Math.max(each - 1, 1), y: top, height: Math.max(bottom - top, 1), fill: color}, g);
+        }
+        if (p.low !== undefined && chart.plot !== "box") {
+          var ex = chart.plot === "line" ? cx : cx + offset + each / 2;
+          el("line", {x1: ex, x2: ex, y1: sy(chart.logY ? Math.max(p.low, lo) : p.low), y2: sy(p.high), stroke: "#222"}, g);
+        }
+        hover(shape, describe(line, i, p));
+      });
+    });
+    // Dragging across the plot zooms the x axis to the dragged range
+    var start = null, band = null;
+    var toX = function(evt) { var r = svg.getBoundingClientRect(); return (evt.clientX - r.left) * width / r.width; };
+    var invert = function(px) {
+      var a = chart.logX ? Math.log10(xr[0]) : xr[0], b = chart.logX ? Math.log10(xr[1]) : xr[1];
+      var v = a + (px - margin.left) / (width - margin.left - margin.right) * (b - a);
+      return chart.logX ? Math.pow(10, v) : v;
+    };
+    svg.addEventListener("mousedown", function(evt) {
+      start = toX(evt);
+      band = el("rect", {"class": "zoom", x: start, y: margin.top, width: 0, height: height - margin.top - margin.bottom}, svg);
+      evt.preventDefault();
+    });
+    svg.addEventListener("mousemove", function(evt) {
+      if (start === null) { return; }
+      var now = toX(evt);
+      band.setAttribute("x", Math.min(start, now));
+      band.setAttribute("width", Math.abs(now - start));
+    });
+    svg.addEventListener("mouseup", function(evt) {
+      if (start === null) { return; }
+      var end = toX(evt);
+      if (Math.abs(end - start) > 5) {
+        var a = invert(Math.min(start, end)), b = invert(Math.max(start, end));
+        zoom = [a, b];
+      }
+      start = null;
+      render();
+    });
+    svg.addEventListener("dblclick", function() { zoom = null; render(); });
+  }
+
+  function drawDeltas(parent) {
+    var width = parent.clientWidth || 800;
+    var rowHeight = 22;
+    var height = rowHeight * chart.deltas.length + margin.top + margin.bottom;
+    var svg = el("svg", {viewBox: "0 0 " + width + " " + height, height: height}, parent);
+    var names = chart.deltas.map(function(d) { return d.name.length; });
+    var left = Math.min(Math.max.apply(null, names.concat([0])) * 7 + 10, width / 2);
+    var max = Math.max.apply(null, chart.deltas.map(function(d) { return Math.min(Math.abs(d.change), 1e6); }).concat([1]));
+    var sx = scale(-max * 1.1, max * 1.1, left, width - margin.right, false);
+    el("line", {"class": "axis", x1: sx(0), x2: sx(0), y1: margin.top, y2: height - margin.bottom}, svg);
+    chart.deltas.forEach(function(d, i) {
+      var y = margin.top + i * rowHeight;
+      var change = Math.max(Math.min(d.change, max), -max);
+      el("text", {x: left - 6, y: y + rowHeight / 2 + 4, "text-anchor": "end"}, svg).textContent = d.name;
+      var bar = el("rect", {x: Math.min(sx(0), sx(change)), width: Math.max(Math.abs(sx(change) - sx(0)), 1), y: y + 3,
+        height: rowHeight - 6, fill: d.improved ? "#7bc46b" : "#f0535f"}, svg);
+      hover(bar, d.name + "\nbaseline: " + fmt(d.baseline) + "\ncandidate: " + fmt(d.candidate) + "\nchange: " + fmt(d.change) + "%");
+    });
+    el("text", {x: (left + width - margin.right) / 2, y: height - 10, "text-anchor": "middle"}, svg).textContent = chart.panels[0].y;
+  }
+
+  function render() {
+    hide();
+    drawLegend();
+    var panels = document.getElementById("panels");
+    panels.innerHTML = "";
+    if (chart.plot === "delta") { drawDeltas(panels); return; }
+    chart.panels.forEach(function(panel, i) {
+      var div = document.createElement("div");
+      div.className = "panel";
+      panels.appendChild(div);
+      drawPanel(panel, div, i === chart.panels.length - 1);
+    });
+  }
+  window.addEventListener("resize", render);
+  render();
+})();
+</script>
+</body>
+</html>
+`))
@@ -0,0 +1,64 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// htmlChartJSON returns the chart a page embeds
+func htmlChartJSON(t *testing.T, page string) htmlChart {
+	start := strings.Index(page, "var chart = ")
+	require.NotEqual(t, -1, start)
+	page = page[start+len("var chart = "):]
+	var ret htmlChart
+	require.NoError(t, json.NewDecoder(strings.NewReader(page)).Decode(&ret))
+	return ret
+}
+
+func TestHTMLPlotter_Plot(t *testing.T) {
+	panels := []PlotPanel{{
+		Y: "ns/op",
+		Lines: []PlotLine{
+			{Name: "<a>", Values: [][]float64{{1, 2, 3}, {}}},
+		},
+	}}
+	var buf bytes.Buffer
+	h := HTMLPlotter{}
+	require.NoError(t, h.Render(Logger{}, &buf, Chart{Title: "title", X: "size", XValues: makeSet("10", "20"), Panels: panels, Type: PlotTypeLine, Aggregation: meanAggregation, ErrorBars: minmaxErrorBars, Scale: AxisScale{NumericX: true}}))
+	page := buf.String()
+	// The page must work offline
+	require.NotContains(t, page, "<script src")
+	require.NotContains(t, page, "<link")
+	require.NotContains(t, page, "<a>")
+	chart := htmlChartJSON(t, page)
+	require.Equal(t, "line", chart.Plot)
+	require.Equal(t, []float64{10, 20}, chart.XPos)
+	require.Equal(t, "<a>", chart.Panels[0].Lines[0].Name)
+	require.Nil(t, chart.Panels[0].Lines[0].Points[1])
+	p := chart.Panels[0].Lines[0].Points[0]
+	require.Equal(t, 2.0, p.Value)
+	require.Equal(t, 3, p.Count)
+	require.Equal(t, 1.0, p.Min)
+	require.Equal(t, 3.0, p.Max)
+	require.Equal(t, 1.0, *p.Low)
+	require.Equal(t, 3.0, *p.High)
+}
+
+func TestHTMLPlotter_PlotDelta(t *testing.T) {
+	var buf bytes.Buffer
+	h := HTMLPlotter{}
+	deltas := []Delta{
+		{Name: "BenchmarkA", Baseline: 10, Candidate: 20},
+		{Name: "BenchmarkB", Baseline: 0, Candidate: 5},
+	}
+	require.NoError(t, h.Render(Logger{}, &buf, Chart{Type: PlotTypeDelta, Unit: "ns/op", Deltas: deltas}))
+	chart := htmlChartJSON(t, buf.String())
+	require.Equal(t, "delta", chart.Plot)
+	require.Equal(t, 100.0, chart.Deltas[0].Change)
+	require.False(t, chart.Deltas[0].Improved)
+	require.Len(t, chart.Deltas, 2)
+}
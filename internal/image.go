@@ -0,0 +1,67 @@
+package internal
+
+import (
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/vgimg"
+)
+
+// ImageFormats are the image formats Plotter can write
+var ImageFormats = []string{"svg", "png", "pdf", "eps", "jpg", "jpeg", "tif", "tiff"}
+
+func containsString(s []string, v string) bool {
+	for _, i := range s {
+		if i == v {
+			return true
+		}
+	}
+	return false
+}
+
+func (c Chart) isRaster() bool {
+	switch c.Format {
+	case "png", "jpg", "jpeg", "tif", "tiff":
+		return true
+	}
+	return false
+}
+
+// imageSize returns the size of the image in points, given the size Plotter would pick for it
+func (c Chart) imageSize(defaultWidth float64, defaultHeight float64) (vg.Length, vg.Length) {
+	// A raster image is w/vg.Inch*dpi pixels wide
+	toPoints := func(v float64) vg.Length {
+		if !c.isRaster() {
+			return vg.Points(v)
+		}
+		dpi := c.Style.DPI
+		if dpi == 0 {
+			dpi = vgimg.DefaultDPI
+		}
+		return vg.Length(v) * vg.Inch / vg.Length(dpi)
+	}
+	// Setting only one of width or height keeps the aspect ratio Plotter picked
+	switch {
+	case c.Style.Width != 0 && c.Style.Height != 0:
+		return toPoints(c.Style.Width), toPoints(c.Style.Height)
+	case c.Style.Width != 0:
+		return toPoints(c.Style.Width), toPoints(c.Style.Width * defaultHeight / defaultWidth)
+	case c.Style.Height != 0:
+		return toPoints(c.Style.Height * defaultWidth / defaultHeight), toPoints(c.Style.Height)
+	}
+	return vg.Points(defaultWidth), vg.Points(defaultHeight)
+}
+
+// newCanvas returns a canvas to draw an image of c's format and resolution on
+func (c Chart) newCanvas(w vg.Length, h vg.Length) (vg.CanvasWriterTo, error) {
+	if c.Style.DPI == 0 || !c.isRaster() {
+		return draw.NewFormattedCanvas(w, h, c.Format)
+	}
+	cv := vgimg.NewWith(vgimg.UseWH(w, h), vgimg.UseDPI(c.Style.DPI))
+	switch c.Format {
+	case "jpg", "jpeg":
+		return vgimg.JpegCanvas{Canvas: cv}, nil
+	case "tif", "tiff":
+		return vgimg.TiffCanvas{Canvas: cv}, nil
+	}
+	return vgimg.PngCanvas{Canvas: cv}, nil
+}
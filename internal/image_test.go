@@ -0,0 +1,25 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gonum.org/v1/plot/vg"
+)
+
+func TestChart_imageSize(t *testing.T) {
+	sizeEqual := func(c Chart, expectedW vg.Length, expectedH vg.Length) func(t *testing.T) {
+		return func(t *testing.T) {
+			w, h := c.imageSize(600, 300)
+			require.InDelta(t, float64(expectedW), float64(w), 1e-9)
+			require.InDelta(t, float64(expectedH), float64(h), 1e-9)
+		}
+	}
+	t.Run("default", sizeEqual(Chart{Format: "svg"}, 600, 300))
+	t.Run("points", sizeEqual(Chart{Format: "svg", Style: ChartStyle{Width: 100, Height: 40}}, 100, 40))
+	t.Run("width", sizeEqual(Chart{Format: "pdf", Style: ChartStyle{Width: 100}}, 100, 50))
+	t.Run("height", sizeEqual(Chart{Format: "pdf", Style: ChartStyle{Height: 100}}, 200, 100))
+	// 96 pixels at the default 96 dpi is an inch
+	t.Run("pixels", sizeEqual(Chart{Format: "png", Style: ChartStyle{Width: 96, Height: 192}}, vg.Inch, 2*vg.Inch))
+	t.Run("dpi", sizeEqual(Chart{Format: "png", Style: ChartStyle{Width: 300, Height: 150, DPI: 300}}, vg.Inch, vg.Inch/2))
+}
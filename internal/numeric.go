@@ -0,0 +1,108 @@
+package internal
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// XScale is how x values are placed along the X axis
+type XScale int
+
+const (
+	_ XScale = iota
+	// XScaleAuto places x values numerically if they are all numbers and the plot is a line plot
+	XScaleAuto
+	// XScaleNominal places x values evenly spaced in order
+	XScaleNominal
+	// XScaleNumeric places x values, which must all be numbers, at their numeric position
+	XScaleNumeric
+)
+
+// ToXScale converts a string name to a known x scale
+func ToXScale(s string) (XScale, error) {
+	switch s {
+	case "", "auto":
+		return XScaleAuto, nil
+	case "nominal":
+		return XScaleNominal, nil
+	case "numeric":
+		return XScaleNumeric, nil
+	}
+	return XScale(0), errors.New("unknown x scale " + s)
+}
+
+// IsNumeric returns true if values should be placed at their numeric position for a plot of type pt.  Auto only
+// places line plots numerically, since bars and boxes for x values that are close together would overlap.
+func (x XScale) IsNumeric(pt PlotType, values OrderedStringSet) (bool, error) {
+	switch x {
+	case XScaleNominal:
+		return false, nil
+	case XScaleNumeric:
+		for _, v := range values.Order {
+			if _, err := ParseNumber(v); err != nil {
+				return false, errors.Wrap(err, "numeric x scale needs numbers")
+			}
+		}
+		return true, nil
+	}
+	if pt != PlotTypeLine || len(values.Order) == 0 {
+		return false, nil
+	}
+	for _, v := range values.Order {
+		if _, err := ParseNumber(v); err != nil {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+var numberSuffixes = []struct {
+	suffix     string
+	multiplier float64
+}{
+	// Longest first, so Ki is checked before K
+	{"Ki", 1 << 10},
+	{"Mi", 1 << 20},
+	{"Gi", 1 << 30},
+	{"Ti", 1 << 40},
+	{"k", 1e3},
+	{"K", 1e3},
+	{"M", 1e6},
+	{"G", 1e9},
+	{"T", 1e12},
+}
+
+// ParseNumber parses a benchmark tag value as a number.  Besides anything strconv.ParseFloat understands (like 1e6),
+// it understands SI suffixes (4k is 4000), binary suffixes (1Mi is 1048576), and an optional trailing B for bytes (1MiB
+// or 1KB).
+func ParseNumber(s string) (float64, error) {
+	f, err := parseNumber(s)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, errors.Errorf("%s is not a number", s)
+	}
+	return f, nil
+}
+
+func parseNumber(s string) (float64, error) {
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f, nil
+	}
+	trimmed := strings.TrimSuffix(s, "B")
+	if f, err := strconv.ParseFloat(trimmed, 64); err == nil && trimmed != s {
+		return f, nil
+	}
+	for _, ns := range numberSuffixes {
+		if !strings.HasSuffix(trimmed, ns.suffix) {
+			continue
+		}
+		f, err := strconv.ParseFloat(strings.TrimSuffix(trimmed, ns.suffix), 64)
+		if err != nil {
+			break
+		}
+		return f * ns.multiplier, nil
+	}
+	return 0, errors.Errorf("%s is not a number", s)
+}
@@ -0,0 +1,66 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNumber(t *testing.T) {
+	numberEqual := func(s string, expected float64) func(t *testing.T) {
+		return func(t *testing.T) {
+			got, err := ParseNumber(s)
+			require.NoError(t, err)
+			require.Equal(t, expected, got)
+		}
+	}
+	t.Run("int", numberEqual("12", 12))
+	t.Run("float", numberEqual("0.999000", 0.999))
+	t.Run("exponent", numberEqual("1e6", 1e6))
+	t.Run("k", numberEqual("4k", 4000))
+	t.Run("K", numberEqual("4K", 4000))
+	t.Run("M", numberEqual("1.5M", 1.5e6))
+	t.Run("KB", numberEqual("2KB", 2000))
+	t.Run("KiB", numberEqual("2KiB", 2048))
+	t.Run("MiB", numberEqual("1MiB", 1<<20))
+	t.Run("Gi", numberEqual("1Gi", 1<<30))
+	t.Run("bytes", numberEqual("64B", 64))
+	mustErr := func(s string) func(t *testing.T) {
+		return func(t *testing.T) {
+			_, err := ParseNumber(s)
+			require.Error(t, err)
+		}
+	}
+	t.Run("word", mustErr("linear"))
+	t.Run("empty", mustErr(""))
+	t.Run("justsuffix", mustErr("KiB"))
+	t.Run("nan", mustErr("NaN"))
+	t.Run("inf", mustErr("inf"))
+	t.Run("commit", mustErr("920af9b"))
+}
+
+func TestXScale_IsNumeric(t *testing.T) {
+	isNumeric := func(scale string, pt PlotType, values OrderedStringSet, expected bool) func(t *testing.T) {
+		return func(t *testing.T) {
+			xs, err := ToXScale(scale)
+			require.NoError(t, err)
+			got, err := xs.IsNumeric(pt, values)
+			require.NoError(t, err)
+			require.Equal(t, expected, got)
+		}
+	}
+	t.Run("autoline", isNumeric("auto", PlotTypeLine, makeSet("1e4", "4k"), true))
+	t.Run("autobar", isNumeric("", PlotTypeBar, makeSet("1e4", "4k"), false))
+	t.Run("autowords", isNumeric("auto", PlotTypeLine, makeSet("1e4", "best"), false))
+	t.Run("autoempty", isNumeric("auto", PlotTypeLine, makeSet(), false))
+	t.Run("nominal", isNumeric("nominal", PlotTypeLine, makeSet("1e4", "4k"), false))
+	t.Run("numericbar", isNumeric("numeric", PlotTypeBar, makeSet("1e4", "4k"), true))
+	t.Run("numericwords", func(t *testing.T) {
+		_, err := XScaleNumeric.IsNumeric(PlotTypeLine, makeSet("1e4", "best"))
+		require.Error(t, err)
+	})
+	t.Run("unknown", func(t *testing.T) {
+		_, err := ToXScale("log")
+		require.Error(t, err)
+	})
+}
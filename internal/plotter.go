@@ -1,13 +1,16 @@
 package internal
 
 import (
+	"image/color"
 	"io"
+	"math"
 
 	"github.com/pkg/errors"
 	"gonum.org/v1/plot"
 	"gonum.org/v1/plot/plotter"
 	"gonum.org/v1/plot/plotutil"
 	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
 )
 
 // Plotter knows how to draw a picture to a writer
@@ -22,6 +25,12 @@ func ToPlotType(s string) (PlotType, error) {
 	if s == "line" {
 		return PlotTypeLine, nil
 	}
+	if s == "box" {
+		return PlotTypeBox, nil
+	}
+	if s == "delta" {
+		return PlotTypeDelta, nil
+	}
 	return PlotType(0), errors.New("unknown plot type " + s)
 }
 
@@ -33,15 +42,53 @@ const (
 	PlotTypeBar
 	// PlotTypeLine is a line graph
 	PlotTypeLine
+	// PlotTypeBox is a box and whisker graph of every value for each x index
+	PlotTypeBox
+	// PlotTypeDelta is a horizontal bar graph of the percent change of each benchmark from a baseline
+	PlotTypeDelta
 )
 
-// Plot will write to out this plot.
-func (l *Plotter) Plot(log Logger, out io.Writer, imgFormat string, pt PlotType, title string, x string, y string, lines []PlotLine, uniqueKeys OrderedStringSet) error {
-	p, err := l.createPlot(log, pt, title, x, y, lines, uniqueKeys.Order)
-	if err != nil {
-		return errors.Wrap(err, "unable to make plot")
+// AxisScale controls how values are placed along the axes of a plot
+type AxisScale struct {
+	// NumericX places x values, which must be numbers, at their numeric position
+	NumericX bool
+	// LogX places x values, which must be positive numbers, on a log scale
+	LogX bool
+	// LogY places y values, which must be positive, on a log scale
+	LogY bool
+	// ReadableY draws y values, and their unit, scaled by ScaleUnit.  Tables and Vega-Lite specs always write values
+	// in their original unit.
+	ReadableY bool
+}
+
+// Render will write to out the chart as an image of its format.  Each panel is drawn as its own plot, stacked
+// vertically, sharing the x axis and legend of the top panel.
+func (l *Plotter) Render(log Logger, out io.Writer, chart Chart) error {
+	if chart.Type == PlotTypeDelta {
+		return l.renderDelta(log, out, chart)
+	}
+	panels := chart.ReadablePanels()
+	if len(panels) == 0 {
+		return errors.New("no panels to plot")
+	}
+	plots := make([]*plot.Plot, 0, len(panels))
+	for i, panel := range panels {
+		p, err := l.createPlot(log, chart.Type, chart.Aggregation, chart.ErrorBars, chart.Scale, chart.Title, chart.X, panel.Y, panel.Lines, chart.XValues.Order)
+		if err != nil {
+			return errors.Wrapf(err, "unable to make plot for %s", panel.Y)
+		}
+		if i != 0 {
+			p.Title.Text = ""
+			if p.Legend, err = plot.NewLegend(); err != nil {
+				return errors.Wrap(err, "unable to make empty legend")
+			}
+		}
+		if i != len(panels)-1 {
+			p.X.Label.Text = ""
+		}
+		plots = append(plots, p)
 	}
-	if err := l.savePlot(out, p, imgFormat, lines, uniqueKeys); err != nil {
+	if err := l.savePlots(out, plots, chart); err != nil {
 		return errors.Wrap(err, "unable to save plot")
 	}
 	return nil
@@ -54,31 +101,142 @@ type PlotLine struct {
 	Values [][]float64
 }
 
-func (l *Plotter) savePlot(out io.Writer, p *plot.Plot, imageFormat string, lines []PlotLine, set OrderedStringSet) error {
-	x := float64(30*(len(lines))*(len(set.Items)) + 290)
-	wt, err := p.WriterTo(vg.Points(x), vg.Points(x/2), imageFormat)
+// PlotPanel is every line of a single y unit.  Every panel of a plot has the same lines in the same order.
+type PlotPanel struct {
+	Y     string
+	Lines []PlotLine
+}
+
+// savePlots draws plots stacked vertically, with aligned axes and a shared x range.
+func (l *Plotter) savePlots(out io.Writer, plots []*plot.Plot, chart Chart) error {
+	x := float64(30*(len(chart.Panels[0].Lines))*(len(chart.XValues.Items)) + 290)
+	w, h := chart.imageSize(x, x/2*float64(len(plots)))
+	wt, err := chart.newCanvas(w, h)
 	if err != nil {
 		return errors.Wrap(err, "unable to make plot writer")
 	}
+	if len(plots) == 1 {
+		plots[0].Draw(draw.New(wt))
+	} else {
+		xMin, xMax := math.Inf(1), math.Inf(-1)
+		for _, p := range plots {
+			xMin = math.Min(xMin, p.X.Min)
+			xMax = math.Max(xMax, p.X.Max)
+		}
+		column := make([][]*plot.Plot, 0, len(plots))
+		for _, p := range plots {
+			p.X.Min = xMin
+			p.X.Max = xMax
+			column = append(column, []*plot.Plot{p})
+		}
+		tiles := draw.Tiles{
+			Rows: len(plots),
+			Cols: 1,
+			PadY: vg.Points(10),
+		}
+		canvases := plot.Align(column, tiles, draw.New(wt))
+		for i, p := range plots {
+			p.Draw(canvases[i][0])
+		}
+	}
 	if _, err := wt.WriteTo(out); err != nil {
 		return errors.Wrap(err, "unable to write plotter to output")
 	}
 	return nil
 }
 
-func (l *Plotter) createPlot(log Logger, pt PlotType, title string, x string, y string, lines []PlotLine, nominalX []string) (*plot.Plot, error) {
+// placement is where createPlot draws each value
+type placement struct {
+	// xPositions is the x location of each x index.  Nil means nominal: each x index is drawn at its index.
+	xPositions []float64
+	// yBase is where bars start.  It is 0 unless y is on a log scale, where 0 cannot be drawn.
+	yBase float64
+}
+
+func (pl placement) x(index int) float64 {
+	if pl.xPositions == nil {
+		return float64(index)
+	}
+	return pl.xPositions[index]
+}
+
+// place moves each aggregated value to its x position.  Empty x indexes are dropped on a log y scale, since their
+// zero value cannot be drawn.
+func (pl placement) place(vals [][]float64, xys plotter.XYs) plotter.XYs {
+	ret := make(plotter.XYs, 0, len(xys))
+	for i, xy := range xys {
+		if pl.yBase != 0 && len(vals[i]) == 0 {
+			continue
+		}
+		ret = append(ret, plotter.XY{X: pl.x(i), Y: xy.Y})
+	}
+	return ret
+}
+
+func makePlacement(scale AxisScale, lines []PlotLine, nominalX []string) (placement, error) {
+	var ret placement
+	if scale.NumericX || scale.LogX {
+		ret.xPositions = make([]float64, 0, len(nominalX))
+		for _, x := range nominalX {
+			pos, err := ParseNumber(x)
+			if err != nil {
+				return ret, errors.Wrap(err, "numeric x axis needs numbers")
+			}
+			if scale.LogX && pos <= 0 {
+				return ret, errors.Errorf("log x axis needs positive numbers, but x value %s is not", x)
+			}
+			ret.xPositions = append(ret.xPositions, pos)
+		}
+	}
+	if scale.LogY {
+		minValue := math.Inf(1)
+		for _, line := range lines {
+			for i, vals := range line.Values {
+				for _, v := range vals {
+					if v <= 0 {
+						return ret, errors.Errorf("log y axis needs positive values, but %s has %v at x value %s", line.Name, v, nominalX[i])
+					}
+					minValue = math.Min(minValue, v)
+				}
+			}
+		}
+		ret.yBase = 1
+		if !math.IsInf(minValue, 1) {
+			ret.yBase = math.Pow10(int(math.Floor(math.Log10(minValue))))
+		}
+	}
+	return ret, nil
+}
+
+func (l *Plotter) createPlot(log Logger, pt PlotType, agg Aggregation, eb ErrorBars, scale AxisScale, title string, x string, y string, lines []PlotLine, nominalX []string) (*plot.Plot, error) {
 	p, err := plot.New()
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to create initial plot")
 	}
+	pm, err := makePlacement(scale, lines, nominalX)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to place values")
+	}
 	p.Title.Text = title
 	p.Y.Label.Text = y
 	p.X.Label.Text = x
-	log.Log(2, "nominal x: %v", nominalX)
-	p.NominalX(nominalX...)
+	if scale.LogX {
+		log.Log(2, "log x: %v", pm.xPositions)
+		p.X.Scale = plot.LogScale{}
+		p.X.Tick.Marker = plot.LogTicks{}
+	} else if scale.NumericX {
+		log.Log(2, "numeric x: %v", pm.xPositions)
+	} else {
+		log.Log(2, "nominal x: %v", nominalX)
+		p.NominalX(nominalX...)
+	}
+	if scale.LogY {
+		p.Y.Scale = plot.LogScale{}
+		p.Y.Tick.Marker = plot.LogTicks{}
+	}
 	p.Legend.Top = true
 	for i, line := range lines {
-		pl, err := l.makePlotter(log, pt, lines, line, i)
+		pl, err := l.makePlotter(log, pt, agg, pm, lines, line, i)
 		if err != nil {
 			return nil, errors.Wrap(err, "unable to make plotter")
 		}
@@ -86,28 +244,79 @@ func (l *Plotter) createPlot(log Logger, pt PlotType, title string, x string, y
 		if asT, ok := pl.(plot.Thumbnailer); ok {
 			p.Legend.Add(line.Name, asT)
 		}
+		// Box plots already show the spread of values
+		if eb != nil && pt != PlotTypeBox {
+			ebp, err := l.addErrorBars(log, pt, agg, eb, pm, line, i, len(lines))
+			if err != nil {
+				return nil, errors.Wrap(err, "unable to make error bars")
+			}
+			p.Add(ebp)
+		}
 	}
 	return p, nil
 }
 
-func (l *Plotter) addBar(log Logger, line PlotLine, offset int, numLines int) (*plotter.BarChart, error) {
+func (l *Plotter) addBar(log Logger, agg Aggregation, pm placement, line PlotLine, offset int, numLines int) (plot.Plotter, error) {
 	w := vg.Points(30)
 	log.Log(2, "adding line %s", line.Name)
-	groupValues := aggregatePlotterValues(line.Values, meanAggregation)
+	groupValues := aggregatePlotterValues(line.Values, agg)
 	log.Log(2, "Values: %v", groupValues)
-	bar, err := plotter.NewBarChart(plotter.YValues{XYer: groupValues}, w)
+	if pm.xPositions == nil {
+		return newBar(plotter.YValues{XYer: groupValues}, 0, pm.yBase, w, offset, numLines)
+	}
+	// Bar charts draw at each integer after XMin.  Numeric x values need a bar chart of their own.
+	ret := &barLine{}
+	for i, vals := range line.Values {
+		if len(vals) == 0 {
+			continue
+		}
+		bar, err := newBar(plotter.Values{groupValues[i].Y}, pm.x(i), pm.yBase, w, offset, numLines)
+		if err != nil {
+			return nil, err
+		}
+		ret.bars = append(ret.bars, bar)
+	}
+	return ret, nil
+}
+
+// newBar makes a bar chart that starts at index xMin.  Bars start from yBase rather than zero, by stacking them on
+// top of a bar chart of yBase that is never drawn.
+func newBar(vals plotter.Valuer, xMin float64, yBase float64, w vg.Length, offset int, numLines int) (*plotter.BarChart, error) {
+	if yBase != 0 {
+		heights := make(plotter.Values, vals.Len())
+		baseHeights := make(plotter.Values, vals.Len())
+		for i := range heights {
+			baseHeights[i] = yBase
+			// A zero value is an empty x index, which has no bar
+			if v := vals.Value(i); v != 0 {
+				heights[i] = v - yBase
+			}
+		}
+		base, err := newBar(baseHeights, xMin, 0, w, offset, numLines)
+		if err != nil {
+			return nil, err
+		}
+		ret, err := newBar(heights, xMin, 0, w, offset, numLines)
+		if err != nil {
+			return nil, err
+		}
+		ret.StackOn(base)
+		return ret, nil
+	}
+	bar, err := plotter.NewBarChart(vals, w)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to make bar chart")
 	}
 	bar.LineStyle.Width = 0
+	bar.XMin = xMin
 	bar.Offset = w * vg.Points(float64(numLines/-2+offset))
 	bar.Color = plotutil.Color(offset)
 	return bar, nil
 }
 
-func (l *Plotter) addLine(log Logger, line PlotLine, offset int) (*plotter.Line, error) {
+func (l *Plotter) addLine(log Logger, agg Aggregation, pm placement, line PlotLine, offset int) (*plotter.Line, error) {
 	log.Log(2, "adding line %s", line.Name)
-	groupValues := aggregatePlotterValues(line.Values, meanAggregation)
+	groupValues := pm.place(line.Values, aggregatePlotterValues(line.Values, agg))
 	log.Log(2, "Values: %v", groupValues)
 	pline, err := plotter.NewLine(groupValues)
 	if err != nil {
@@ -118,31 +327,237 @@ func (l *Plotter) addLine(log Logger, line PlotLine, offset int) (*plotter.Line,
 	return pline, nil
 }
 
-func (l *Plotter) makePlotter(log Logger, pt PlotType, lines []PlotLine, line PlotLine, index int) (plot.Plotter, error) {
+func (l *Plotter) addErrorBars(log Logger, pt PlotType, agg Aggregation, eb ErrorBars, pm placement, line PlotLine, offset int, numLines int) (*offsetErrorBars, error) {
+	points := makeErrorPoints(line.Values, agg, eb)
+	for i := range points.XYs {
+		points.XYs[i].X = pm.x(int(points.XYs[i].X))
+		// On a log scale, error bars cannot reach zero
+		if pm.yBase != 0 {
+			points.YErrors[i].Low = math.Min(points.YErrors[i].Low, points.XYs[i].Y-pm.yBase)
+		}
+	}
+	log.Log(2, "Error bars: %v", points.YErrors)
+	bars, err := plotter.NewYErrorBars(points)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to make error bars")
+	}
+	ret := &offsetErrorBars{
+		YErrorBars: bars,
+	}
 	if pt == PlotTypeBar {
-		return l.addBar(log, line, index, len(lines))
+		w := vg.Points(30)
+		ret.Offset = w * vg.Points(float64(numLines/-2+offset))
+	} else {
+		ret.Color = plotutil.Color(offset)
 	}
-	return l.addLine(log, line, index)
+	return ret, nil
 }
 
-func aggregatePlotterValues(f [][]float64, aggregation func([]float64) float64) plotter.XYer {
-	var ret plotter.XYs
-	for i, x := range f {
-		ret = append(ret, plotter.XY{
-			X: float64(i),
-			Y: aggregation(x),
-		})
+func (l *Plotter) addBox(log Logger, pm placement, line PlotLine, offset int, numLines int) (*boxLine, error) {
+	w := vg.Points(30)
+	log.Log(2, "adding line %s", line.Name)
+	log.Log(2, "Values: %v", line.Values)
+	ret := &boxLine{
+		color: plotutil.Color(offset),
+	}
+	for i, vals := range line.Values {
+		// A box plot of nothing has no meaning.  Leave a gap at this index.
+		if len(vals) == 0 {
+			continue
+		}
+		box, err := plotter.NewBoxPlot(w-vg.Points(6), pm.x(i), plotter.Values(vals))
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to make box plot")
+		}
+		box.Offset = w * vg.Points(float64(numLines/-2+offset))
+		box.BoxStyle.Color = ret.color
+		box.MedianStyle.Color = ret.color
+		box.WhiskerStyle.Color = ret.color
+		box.GlyphStyle.Color = ret.color
+		ret.boxes = append(ret.boxes, box)
+	}
+	return ret, nil
+}
+
+func (l *Plotter) makePlotter(log Logger, pt PlotType, agg Aggregation, pm placement, lines []PlotLine, line PlotLine, index int) (plot.Plotter, error) {
+	if pt == PlotTypeBar {
+		return l.addBar(log, agg, pm, line, index, len(lines))
+	}
+	if pt == PlotTypeBox {
+		return l.addBox(log, pm, line, index, len(lines))
+	}
+	return l.addLine(log, agg, pm, line, index)
+}
+
+// barLine is a bar chart for each x value of a single PlotLine.  It lets bars be drawn at numeric x positions and
+// still share a single legend entry.
+type barLine struct {
+	bars []*plotter.BarChart
+}
+
+var _ plot.Plotter = &barLine{}
+var _ plot.DataRanger = &barLine{}
+var _ plot.GlyphBoxer = &barLine{}
+var _ plot.Thumbnailer = &barLine{}
+
+// Plot draws each bar
+func (b *barLine) Plot(c draw.Canvas, plt *plot.Plot) {
+	for _, bar := range b.bars {
+		bar.Plot(c, plt)
+	}
+}
+
+// DataRange returns the range of all bars combined
+func (b *barLine) DataRange() (xmin, xmax, ymin, ymax float64) {
+	rangers := make([]plot.DataRanger, 0, len(b.bars))
+	for _, bar := range b.bars {
+		rangers = append(rangers, bar)
+	}
+	return combinedDataRange(rangers)
+}
+
+// GlyphBoxes returns the glyph boxes of every bar
+func (b *barLine) GlyphBoxes(plt *plot.Plot) []plot.GlyphBox {
+	ret := make([]plot.GlyphBox, 0, len(b.bars))
+	for _, bar := range b.bars {
+		ret = append(ret, bar.GlyphBoxes(plt)...)
 	}
 	return ret
 }
 
-func meanAggregation(vals []float64) float64 {
-	if len(vals) == 0 {
-		return 0
+// Thumbnail draws the same thumbnail as any one of the bars
+func (b *barLine) Thumbnail(c *draw.Canvas) {
+	if len(b.bars) > 0 {
+		b.bars[0].Thumbnail(c)
+	}
+}
+
+// combinedDataRange is the smallest range containing every DataRanger
+func combinedDataRange(rangers []plot.DataRanger) (xmin, xmax, ymin, ymax float64) {
+	if len(rangers) == 0 {
+		return 0, 0, 0, 0
+	}
+	xmin, xmax, ymin, ymax = rangers[0].DataRange()
+	for _, r := range rangers[1:] {
+		rxmin, rxmax, rymin, rymax := r.DataRange()
+		xmin = math.Min(xmin, rxmin)
+		xmax = math.Max(xmax, rxmax)
+		ymin = math.Min(ymin, rymin)
+		ymax = math.Max(ymax, rymax)
+	}
+	return xmin, xmax, ymin, ymax
+}
+
+// boxLine is every box plot of a single PlotLine.  It lets a line of box plots share a single legend entry.
+type boxLine struct {
+	boxes []*plotter.BoxPlot
+	color color.Color
+}
+
+var _ plot.Plotter = &boxLine{}
+var _ plot.DataRanger = &boxLine{}
+var _ plot.GlyphBoxer = &boxLine{}
+var _ plot.Thumbnailer = &boxLine{}
+
+// Plot draws each box
+func (b *boxLine) Plot(c draw.Canvas, plt *plot.Plot) {
+	for _, box := range b.boxes {
+		box.Plot(c, plt)
 	}
-	sum := 0.0
-	for _, v := range vals {
-		sum += v
+}
+
+// DataRange returns the range of all boxes combined
+func (b *boxLine) DataRange() (xmin, xmax, ymin, ymax float64) {
+	rangers := make([]plot.DataRanger, 0, len(b.boxes))
+	for _, box := range b.boxes {
+		rangers = append(rangers, box)
 	}
-	return sum / float64(len(vals))
+	return combinedDataRange(rangers)
+}
+
+// GlyphBoxes returns the glyph boxes of every box
+func (b *boxLine) GlyphBoxes(plt *plot.Plot) []plot.GlyphBox {
+	ret := make([]plot.GlyphBox, 0, len(b.boxes))
+	for _, box := range b.boxes {
+		ret = append(ret, box.GlyphBoxes(plt)...)
+	}
+	return ret
+}
+
+// Thumbnail draws a filled rectangle of this line's color, the same as a bar chart
+func (b *boxLine) Thumbnail(c *draw.Canvas) {
+	pts := []vg.Point{
+		{X: c.Min.X, Y: c.Min.Y},
+		{X: c.Min.X, Y: c.Max.Y},
+		{X: c.Max.X, Y: c.Max.Y},
+		{X: c.Max.X, Y: c.Min.Y},
+	}
+	c.FillPolygon(b.color, c.ClipPolygonY(pts))
+}
+
+// renderDelta will write to out a horizontal bar for each delta's percent change.  Deltas should be sorted in the order
+// to draw them, top to bottom.  Improvements are green and regressions are red.
+func (l *Plotter) renderDelta(log Logger, out io.Writer, chart Chart) error {
+	p, err := l.createDeltaPlot(log, chart.Title, chart.Unit, chart.Deltas)
+	if err != nil {
+		return errors.Wrap(err, "unable to make plot")
+	}
+	w, h := chart.imageSize(800, float64(20*len(chart.Deltas)+120))
+	wt, err := chart.newCanvas(w, h)
+	if err != nil {
+		return errors.Wrap(err, "unable to make plot writer")
+	}
+	p.Draw(draw.New(wt))
+	if _, err := wt.WriteTo(out); err != nil {
+		return errors.Wrap(err, "unable to write plotter to output")
+	}
+	return nil
+}
+
+func (l *Plotter) createDeltaPlot(log Logger, title string, y string, deltas []Delta) (*plot.Plot, error) {
+	p, err := plot.New()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create initial plot")
+	}
+	p.Title.Text = title
+	p.X.Label.Text = "% change in " + y
+	// NominalY draws index 0 at the bottom, but we want the first delta at the top
+	names := make([]string, len(deltas))
+	improved := make(plotter.Values, len(deltas))
+	regressed := make(plotter.Values, len(deltas))
+	for i, d := range deltas {
+		idx := len(deltas) - 1 - i
+		names[idx] = d.Name
+		change := d.PercentChange()
+		if math.IsInf(change, 0) {
+			log.Log(1, "unable to draw change of %s from zero", d.Name)
+			continue
+		}
+		if (change < 0) != HigherIsBetter(y) {
+			improved[idx] = change
+		} else {
+			regressed[idx] = change
+		}
+	}
+	log.Log(2, "nominal y: %v", names)
+	p.NominalY(names...)
+	w := vg.Points(15)
+	for _, bars := range []struct {
+		vals  plotter.Values
+		color color.Color
+	}{
+		{vals: improved, color: color.RGBA{G: 160, A: 255}},
+		{vals: regressed, color: color.RGBA{R: 200, A: 255}},
+	} {
+		bar, err := plotter.NewBarChart(bars.vals, w)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to make bar chart")
+		}
+		bar.Horizontal = true
+		bar.LineStyle.Width = 0
+		bar.Color = bars.color
+		p.Add(bar)
+	}
+	p.Add(plotter.NewGrid())
+	return p, nil
 }
@@ -0,0 +1,45 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMakePlacement(t *testing.T) {
+	lines := []PlotLine{
+		{Name: "a", Values: [][]float64{{150, 2000}, {}}},
+	}
+	t.Run("nominal", func(t *testing.T) {
+		pm, err := makePlacement(AxisScale{}, lines, []string{"x", "y"})
+		require.NoError(t, err)
+		require.Nil(t, pm.xPositions)
+		require.Equal(t, 0.0, pm.yBase)
+		require.Equal(t, 1.0, pm.x(1))
+	})
+	t.Run("logx", func(t *testing.T) {
+		pm, err := makePlacement(AxisScale{LogX: true}, lines, []string{"1e4", "10"})
+		require.NoError(t, err)
+		require.Equal(t, []float64{1e4, 10}, pm.xPositions)
+		require.Equal(t, 10.0, pm.x(1))
+	})
+	t.Run("logxnotnumber", func(t *testing.T) {
+		_, err := makePlacement(AxisScale{LogX: true}, lines, []string{"x", "y"})
+		require.Error(t, err)
+	})
+	t.Run("logxzero", func(t *testing.T) {
+		_, err := makePlacement(AxisScale{LogX: true}, lines, []string{"0", "1"})
+		require.Error(t, err)
+	})
+	t.Run("logy", func(t *testing.T) {
+		pm, err := makePlacement(AxisScale{LogY: true}, lines, []string{"x", "y"})
+		require.NoError(t, err)
+		require.Equal(t, 100.0, pm.yBase)
+		xys := pm.place(lines[0].Values, aggregatePlotterValues(lines[0].Values, meanAggregation))
+		require.Len(t, xys, 1)
+	})
+	t.Run("logynegative", func(t *testing.T) {
+		_, err := makePlacement(AxisScale{LogY: true}, []PlotLine{{Values: [][]float64{{1, 0}}}}, []string{"x"})
+		require.Error(t, err)
+	})
+}
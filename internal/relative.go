@@ -0,0 +1,28 @@
+package internal
+
+// RelativeLines returns lines with every value divided by the baseline line's aggregated value at the same x index.
+// An x index where the baseline has no values, or a zero value, is left with no values.
+func RelativeLines(lines []PlotLine, baseline PlotLine, agg Aggregation) []PlotLine {
+	ret := make([]PlotLine, 0, len(lines))
+	for _, line := range lines {
+		rl := PlotLine{
+			Name:   line.Name,
+			Values: make([][]float64, len(line.Values)),
+		}
+		for i, vals := range line.Values {
+			if i >= len(baseline.Values) || len(baseline.Values[i]) == 0 {
+				continue
+			}
+			base := agg(baseline.Values[i])
+			if base == 0 {
+				continue
+			}
+			rl.Values[i] = make([]float64, 0, len(vals))
+			for _, v := range vals {
+				rl.Values[i] = append(rl.Values[i], v/base)
+			}
+		}
+		ret = append(ret, rl)
+	}
+	return ret
+}
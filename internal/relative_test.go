@@ -0,0 +1,19 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelativeLines(t *testing.T) {
+	lines := []PlotLine{
+		{Name: "base", Values: [][]float64{{2, 4}, {10}, {}, {0}}},
+		{Name: "other", Values: [][]float64{{6}, {5, 20}, {1}, {1}}},
+	}
+	got := RelativeLines(lines, lines[0], meanAggregation)
+	require.Equal(t, []PlotLine{
+		{Name: "base", Values: [][]float64{{2.0 / 3, 4.0 / 3}, {1}, nil, nil}},
+		{Name: "other", Values: [][]float64{{2}, {0.5, 2}, nil, nil}},
+	}, got)
+}
@@ -0,0 +1,165 @@
+package internal
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Chart is everything needed to draw one picture of benchmark values
+type Chart struct {
+	Title string
+	// X is the key drawn on the x axis, and XValues is every value of it in the order they are drawn
+	X       string
+	XValues OrderedStringSet
+	// Panels are drawn stacked vertically, one per y unit, sharing the x axis
+	Panels []PlotPanel
+	Type   PlotType
+	// Aggregation turns the samples at each x value into the single value drawn
+	Aggregation Aggregation
+	// ErrorBars, if not nil, draws the spread of the samples at each x value
+	ErrorBars ErrorBars
+	Scale     AxisScale
+	// Deltas are drawn instead of Panels by delta charts.  Unit is the y unit they are a change in.
+	Deltas []Delta
+	Unit   string
+	// Format is the name the chart's Renderer is registered as
+	Format string
+	Style  ChartStyle
+}
+
+// ChartStyle controls how big a chart is drawn
+type ChartStyle struct {
+	// Width and Height override the size a Renderer picks.  For images they are pixels for png, jpg and tif images
+	// and points for everything else, and for term Width is columns.  Zero means pick a size from the number of
+	// values drawn.
+	Width  float64
+	Height float64
+	// DPI is the resolution of png, jpg and tif images.  Zero means the gonum default of 96.
+	DPI int
+}
+
+// Renderer draws a Chart to a writer
+type Renderer interface {
+	Render(log Logger, out io.Writer, chart Chart) error
+}
+
+// RendererFunc allows a function to be a Renderer
+type RendererFunc func(log Logger, out io.Writer, chart Chart) error
+
+// Render calls f
+func (f RendererFunc) Render(log Logger, out io.Writer, chart Chart) error {
+	return f(log, out, chart)
+}
+
+// Registry finds the Renderer of a format name.  The zero value knows every built in format.  It is safe to use
+// from many goroutines at once.
+type Registry struct {
+	once      sync.Once
+	mu        sync.RWMutex
+	formats   []string
+	renderers map[string]Renderer
+}
+
+// builtinFormats are the formats of Registry, in the order Formats lists them
+var builtinFormats = append(append(append([]string(nil), ImageFormats...), "term", "html", "vegalite"), TableFormats...)
+
+func builtinRenderer(format string) Renderer {
+	switch format {
+	case "term":
+		return &TermPlotter{}
+	case "html":
+		return &HTMLPlotter{}
+	case "vegalite":
+		return &VegaLitePlotter{}
+	}
+	if containsString(TableFormats, format) {
+		return &TablePlotter{Format: format}
+	}
+	return &Plotter{}
+}
+
+func (r *Registry) init() {
+	r.once.Do(func() {
+		r.renderers = make(map[string]Renderer, len(builtinFormats))
+		for _, f := range builtinFormats {
+			r.formats = append(r.formats, f)
+			r.renderers[f] = builtinRenderer(f)
+		}
+	})
+}
+
+// Register sets the Renderer of format, replacing any Renderer already registered for it
+func (r *Registry) Register(format string, renderer Renderer) {
+	r.init()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.renderers[format]; !exists {
+		r.formats = append(r.formats, format)
+	}
+	r.renderers[format] = renderer
+}
+
+// Renderer returns the Renderer of format
+func (r *Registry) Renderer(format string) (Renderer, error) {
+	r.init()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ret, exists := r.renderers[format]
+	if !exists {
+		return nil, r.unknownFormat(format)
+	}
+	return ret, nil
+}
+
+// unknownFormat is the error of a format that is not registered.  It must be called with mu held.
+func (r *Registry) unknownFormat(format string) error {
+	return errors.Errorf("unknown format %s: supported formats are %s", format, strings.Join(r.formats, ","))
+}
+
+// Formats returns every registered format name
+func (r *Registry) Formats() []string {
+	r.init()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]string(nil), r.formats...)
+}
+
+// ToFormat checks format is registered.  An empty format is inferred from the extension of the output file name,
+// and is svg if that is not a registered format either.
+func (r *Registry) ToFormat(format string, output string) (string, error) {
+	r.init()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if format == "" {
+		ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(output), "."))
+		if ext == "md" {
+			ext = "markdown"
+		}
+		if _, exists := r.renderers[ext]; exists {
+			return ext, nil
+		}
+		return "svg", nil
+	}
+	if _, exists := r.renderers[format]; !exists {
+		return "", r.unknownFormat(format)
+	}
+	return format, nil
+}
+
+// isTerminal returns true if w is a terminal, which can draw colors and Unicode
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return stat.Mode()&os.ModeCharDevice != 0
+}
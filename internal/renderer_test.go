@@ -0,0 +1,101 @@
+package internal
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_ToFormat(t *testing.T) {
+	formatEqual := func(format string, output string, expected string) func(t *testing.T) {
+		return func(t *testing.T) {
+			var r Registry
+			got, err := r.ToFormat(format, output)
+			require.NoError(t, err)
+			require.Equal(t, expected, got)
+		}
+	}
+	t.Run("default", formatEqual("", "", "svg"))
+	t.Run("stdout", formatEqual("", "-", "svg"))
+	t.Run("png", formatEqual("", "chart.png", "png"))
+	t.Run("markdown", formatEqual("", "README.md", "markdown"))
+	t.Run("csv", formatEqual("", "data.csv", "csv"))
+	t.Run("upper", formatEqual("", "out/chart.PDF", "pdf"))
+	t.Run("unknownext", formatEqual("", "chart.txt", "svg"))
+	t.Run("explicit", formatEqual("eps", "chart.png", "eps"))
+	t.Run("unknown", func(t *testing.T) {
+		var r Registry
+		_, err := r.ToFormat("gif", "chart.png")
+		require.Error(t, err)
+	})
+}
+
+func TestRegistry_Register(t *testing.T) {
+	var r Registry
+	require.Contains(t, r.Formats(), "svg")
+	_, err := r.Renderer("txt")
+	require.Error(t, err)
+
+	r.Register("txt", RendererFunc(func(log Logger, out io.Writer, chart Chart) error {
+		_, err := io.WriteString(out, chart.Title)
+		return err
+	}))
+	require.Equal(t, "txt", r.Formats()[len(r.Formats())-1])
+	format, err := r.ToFormat("", "chart.txt")
+	require.NoError(t, err)
+	require.Equal(t, "txt", format)
+	renderer, err := r.Renderer(format)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, renderer.Render(Logger{}, &buf, Chart{Title: "title"}))
+	require.Equal(t, "title", buf.String())
+
+	// Registering a built in format replaces it
+	r.Register("svg", renderer)
+	renderer, err = r.Renderer("svg")
+	require.NoError(t, err)
+	buf.Reset()
+	require.NoError(t, renderer.Render(Logger{}, &buf, Chart{Title: "svg"}))
+	require.Equal(t, "svg", buf.String())
+	require.Len(t, r.Formats(), len(builtinFormats)+1)
+}
+
+func TestRegistry_concurrent(t *testing.T) {
+	var r Registry
+	var wg sync.WaitGroup
+	// start lets every goroutine use r at once.  Goroutines cannot fail the test, so they send their errors to errs.
+	start := make(chan struct{})
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			if i%2 == 0 {
+				r.Register("custom"+strconv.Itoa(i), RendererFunc(func(log Logger, out io.Writer, chart Chart) error {
+					return nil
+				}))
+			}
+			format, err := r.ToFormat("", "chart.png")
+			if err == nil {
+				_, err = r.Renderer(format)
+			}
+			if err == nil && !containsString(r.Formats(), "svg") {
+				err = errors.New("svg is not a format")
+			}
+			errs <- err
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+	require.Len(t, r.Formats(), len(builtinFormats)+4)
+}
@@ -0,0 +1,207 @@
+package internal
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// SortOrder is how to order x values or groups
+type SortOrder int
+
+const (
+	_ SortOrder = iota
+	// SortInput keeps the order values are first seen in the benchmark input
+	SortInput
+	// SortAlpha orders values alphabetically
+	SortAlpha
+	// SortNatural orders values alphabetically, except runs of digits compare as numbers.  size=2 is before size=10.
+	SortNatural
+	// SortNumeric orders values, which must all be numbers, by their number
+	SortNumeric
+	// SortValue orders values by their aggregated y value, smallest first
+	SortValue
+)
+
+// ToSortOrder converts a string name to a known sort order
+func ToSortOrder(s string) (SortOrder, error) {
+	switch s {
+	case "", "input":
+		return SortInput, nil
+	case "alpha":
+		return SortAlpha, nil
+	case "natural":
+		return SortNatural, nil
+	case "numeric":
+		return SortNumeric, nil
+	case "value":
+		return SortValue, nil
+	}
+	return SortOrder(0), errors.New("unknown sort order " + s)
+}
+
+// NaturalLess returns true if a is before b in natural order: runs of digits are compared as numbers and everything
+// else is compared as strings.
+func NaturalLess(a string, b string) bool {
+	for len(a) > 0 && len(b) > 0 {
+		aChunk, aDigits := nextChunk(a)
+		bChunk, bDigits := nextChunk(b)
+		a, b = a[len(aChunk):], b[len(bChunk):]
+		if aChunk == bChunk {
+			continue
+		}
+		if aDigits && bDigits {
+			aTrim, bTrim := strings.TrimLeft(aChunk, "0"), strings.TrimLeft(bChunk, "0")
+			if len(aTrim) != len(bTrim) {
+				return len(aTrim) < len(bTrim)
+			}
+			if aTrim != bTrim {
+				return aTrim < bTrim
+			}
+			// Equal numbers, like 01 and 1.  Fall back to the strings so the order is stable.
+		}
+		return aChunk < bChunk
+	}
+	return len(a) < len(b)
+}
+
+// nextChunk returns the leading run of s that is all digits or all not digits
+func nextChunk(s string) (string, bool) {
+	digits := unicode.IsDigit(rune(s[0]))
+	for i, r := range s {
+		if unicode.IsDigit(r) != digits {
+			return s[:i], digits
+		}
+	}
+	return s, digits
+}
+
+// sortedIndexes returns the indexes of names in sorted order.  values are the y values of each name, used by
+// SortValue.
+func sortedIndexes(so SortOrder, agg Aggregation, names []string, values [][]float64) ([]int, error) {
+	ret := make([]int, len(names))
+	for i := range ret {
+		ret[i] = i
+	}
+	var less func(a int, b int) bool
+	switch so {
+	case SortInput:
+		return ret, nil
+	case SortAlpha:
+		less = func(a int, b int) bool {
+			return names[a] < names[b]
+		}
+	case SortNatural:
+		less = func(a int, b int) bool {
+			return NaturalLess(names[a], names[b])
+		}
+	case SortNumeric:
+		numbers := make([]float64, len(names))
+		for i, n := range names {
+			num, err := ParseNumber(n)
+			if err != nil {
+				return nil, errors.Wrap(err, "numeric sort needs numbers")
+			}
+			numbers[i] = num
+		}
+		less = func(a int, b int) bool {
+			return numbers[a] < numbers[b]
+		}
+	case SortValue:
+		aggregated := make([]float64, len(names))
+		for i, v := range values {
+			// Nothing to sort by goes last
+			aggregated[i] = math.Inf(1)
+			if len(v) > 0 {
+				aggregated[i] = agg(v)
+			}
+		}
+		less = func(a int, b int) bool {
+			return aggregated[a] < aggregated[b]
+		}
+	default:
+		return nil, errors.New("unknown sort order")
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		return less(ret[i], ret[j])
+	})
+	return ret, nil
+}
+
+// SortX returns the x values, and each panel's line values for them, in sort order so.  SortValue orders by the
+// aggregation of every line's values for an x in the first panel, since other panels have different units.
+func SortX(so SortOrder, agg Aggregation, xValues OrderedStringSet, panels []PlotPanel) (OrderedStringSet, []PlotPanel, error) {
+	allValues := make([][]float64, len(xValues.Order))
+	if len(panels) > 0 {
+		for _, line := range panels[0].Lines {
+			for i, vals := range line.Values {
+				allValues[i] = append(allValues[i], vals...)
+			}
+		}
+	}
+	order, err := sortedIndexes(so, agg, xValues.Order, allValues)
+	if err != nil {
+		return xValues, panels, err
+	}
+	var retX OrderedStringSet
+	for _, idx := range order {
+		retX.Add(xValues.Order[idx])
+	}
+	retPanels := make([]PlotPanel, 0, len(panels))
+	for _, panel := range panels {
+		sortedPanel := PlotPanel{
+			Y:     panel.Y,
+			Lines: make([]PlotLine, 0, len(panel.Lines)),
+		}
+		for _, line := range panel.Lines {
+			sorted := PlotLine{
+				Name:   line.Name,
+				Values: make([][]float64, 0, len(line.Values)),
+			}
+			for _, idx := range order {
+				sorted.Values = append(sorted.Values, line.Values[idx])
+			}
+			sortedPanel.Lines = append(sortedPanel.Lines, sorted)
+		}
+		retPanels = append(retPanels, sortedPanel)
+	}
+	return retX, retPanels, nil
+}
+
+// SortLines returns each panel's lines in sort order so, using each line's Name.  Every panel must have the same
+// lines in the same order, so they are drawn with the same colors.  SortValue orders by the aggregation of all a
+// line's values in the first panel.
+func SortLines(so SortOrder, agg Aggregation, panels []PlotPanel) ([]PlotPanel, error) {
+	if len(panels) == 0 {
+		return panels, nil
+	}
+	names := make([]string, 0, len(panels[0].Lines))
+	allValues := make([][]float64, 0, len(panels[0].Lines))
+	for _, line := range panels[0].Lines {
+		names = append(names, line.Name)
+		var vals []float64
+		for _, v := range line.Values {
+			vals = append(vals, v...)
+		}
+		allValues = append(allValues, vals)
+	}
+	order, err := sortedIndexes(so, agg, names, allValues)
+	if err != nil {
+		return panels, err
+	}
+	ret := make([]PlotPanel, 0, len(panels))
+	for _, panel := range panels {
+		sortedPanel := PlotPanel{
+			Y:     panel.Y,
+			Lines: make([]PlotLine, 0, len(panel.Lines)),
+		}
+		for _, idx := range order {
+			sortedPanel.Lines = append(sortedPanel.Lines, panel.Lines[idx])
+		}
+		ret = append(ret, sortedPanel)
+	}
+	return ret, nil
+}
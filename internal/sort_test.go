@@ -0,0 +1,89 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNaturalLess(t *testing.T) {
+	require.True(t, NaturalLess("size=2", "size=10"))
+	require.False(t, NaturalLess("size=10", "size=2"))
+	require.True(t, NaturalLess("a", "b"))
+	require.True(t, NaturalLess("a", "a1"))
+	require.True(t, NaturalLess("a01", "a1"))
+	require.False(t, NaturalLess("a1", "a1"))
+	require.True(t, NaturalLess("1e4", "1e5"))
+	require.True(t, NaturalLess("v1.2.9", "v1.10.0"))
+}
+
+func TestSortX(t *testing.T) {
+	xValues := makeSet("size=10", "size=2", "size=1")
+	lines := []PlotLine{
+		{Name: "a", Values: [][]float64{{1}, {5}, {3}}},
+		{Name: "b", Values: [][]float64{{2}, {}, {6}}},
+	}
+	sortEqual := func(order string, expectedX OrderedStringSet, expectedLines []PlotLine) func(t *testing.T) {
+		return func(t *testing.T) {
+			so, err := ToSortOrder(order)
+			require.NoError(t, err)
+			gotX, gotPanels, err := SortX(so, meanAggregation, xValues, []PlotPanel{{Y: "ns/op", Lines: lines}})
+			require.NoError(t, err)
+			require.Equal(t, expectedX, gotX)
+			require.Equal(t, []PlotPanel{{Y: "ns/op", Lines: expectedLines}}, gotPanels)
+		}
+	}
+	t.Run("input", sortEqual("input", xValues, lines))
+	t.Run("alpha", sortEqual("alpha", makeSet("size=1", "size=10", "size=2"), []PlotLine{
+		{Name: "a", Values: [][]float64{{3}, {1}, {5}}},
+		{Name: "b", Values: [][]float64{{6}, {2}, {}}},
+	}))
+	t.Run("natural", sortEqual("natural", makeSet("size=1", "size=2", "size=10"), []PlotLine{
+		{Name: "a", Values: [][]float64{{3}, {5}, {1}}},
+		{Name: "b", Values: [][]float64{{6}, {}, {2}}},
+	}))
+	t.Run("value", sortEqual("value", makeSet("size=10", "size=1", "size=2"), []PlotLine{
+		{Name: "a", Values: [][]float64{{1}, {3}, {5}}},
+		{Name: "b", Values: [][]float64{{2}, {6}, {}}},
+	}))
+	t.Run("numericwords", func(t *testing.T) {
+		_, _, err := SortX(SortNumeric, meanAggregation, xValues, []PlotPanel{{Y: "ns/op", Lines: lines}})
+		require.Error(t, err)
+	})
+	t.Run("panels", func(t *testing.T) {
+		panels := []PlotPanel{
+			{Y: "ns/op", Lines: lines},
+			{Y: "B/op", Lines: []PlotLine{
+				{Name: "a", Values: [][]float64{{100}, {1}, {10}}},
+				{Name: "b", Values: [][]float64{{100}, {2}, {20}}},
+			}},
+		}
+		_, gotPanels, err := SortX(SortValue, meanAggregation, xValues, panels)
+		require.NoError(t, err)
+		require.Equal(t, [][]float64{{100}, {10}, {1}}, gotPanels[1].Lines[0].Values)
+	})
+	t.Run("numeric", func(t *testing.T) {
+		gotX, _, err := SortX(SortNumeric, meanAggregation, makeSet("1e4", "4k", "10"), nil)
+		require.NoError(t, err)
+		require.Equal(t, makeSet("10", "4k", "1e4"), gotX)
+	})
+	t.Run("unknown", func(t *testing.T) {
+		_, err := ToSortOrder("random")
+		require.Error(t, err)
+	})
+}
+
+func TestSortLines(t *testing.T) {
+	lines := []PlotLine{
+		{Name: "size=10", Values: [][]float64{{1}, {5}}},
+		{Name: "size=2", Values: [][]float64{{}, {}}},
+		{Name: "size=1", Values: [][]float64{{2}, {6}}},
+	}
+	panels := []PlotPanel{{Y: "ns/op", Lines: lines}}
+	got, err := SortLines(SortNatural, meanAggregation, panels)
+	require.NoError(t, err)
+	require.Equal(t, []PlotPanel{{Y: "ns/op", Lines: []PlotLine{lines[2], lines[1], lines[0]}}}, got)
+	got, err = SortLines(SortValue, meanAggregation, panels)
+	require.NoError(t, err)
+	require.Equal(t, []PlotPanel{{Y: "ns/op", Lines: []PlotLine{lines[0], lines[2], lines[1]}}}, got)
+}
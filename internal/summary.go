@@ -0,0 +1,103 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// BenchmarkSummary is what a top level benchmark has to draw: its keys, each key's values and its units
+type BenchmarkSummary struct {
+	Name string
+	// Results is how many benchmark results have this name
+	Results int
+	Keys    []KeySummary
+	Units   []UnitSummary
+}
+
+// KeySummary is every value of a key, in the order they are first seen
+type KeySummary struct {
+	Key    string
+	Values []string
+}
+
+// UnitSummary is how many results have a unit
+type UnitSummary struct {
+	Unit    string
+	Samples int
+}
+
+// SummarizeBenchmarks returns a summary of each top level benchmark name of results, in the order they are first seen
+func SummarizeBenchmarks(results BenchmarkList) []BenchmarkSummary {
+	var names OrderedStringSet
+	byName := make(map[string]BenchmarkList)
+	for _, r := range results {
+		name := TopLevelName(r.Name)
+		names.Add(name)
+		byName[name] = append(byName[name], r)
+	}
+	ret := make([]BenchmarkSummary, 0, len(names.Order))
+	for _, name := range names.Order {
+		list := byName[name]
+		s := BenchmarkSummary{
+			Name:    name,
+			Results: len(list),
+		}
+		for _, k := range list.AllKeys().Order {
+			// The name is a key of every result, without a value
+			if k == name {
+				continue
+			}
+			s.Keys = append(s.Keys, KeySummary{
+				Key:    k,
+				Values: list.UniqueValuesForKey(k).Order,
+			})
+		}
+		for _, u := range list.AllUnits().Order {
+			us := UnitSummary{Unit: u}
+			for _, r := range list {
+				if _, exists := r.ValueByUnit(u); exists {
+					us.Samples++
+				}
+			}
+			s.Units = append(s.Units, us)
+		}
+		ret = append(ret, s)
+	}
+	return ret
+}
+
+// WriteSummaries writes each summary as text, with a line for each key and its values and each unit and its samples
+func WriteSummaries(out io.Writer, summaries []BenchmarkSummary) error {
+	var sb strings.Builder
+	for i, s := range summaries {
+		if i != 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(fmt.Sprintf("%s (%d results)\n", s.Name, s.Results))
+		keys := make([]string, 0, len(s.Keys))
+		for _, k := range s.Keys {
+			keys = append(keys, k.Key)
+		}
+		units := make([]string, 0, len(s.Units))
+		for _, u := range s.Units {
+			units = append(units, u.Unit)
+		}
+		sb.WriteString("  keys:\n")
+		keyWidth := maxWidth(keys)
+		for _, k := range s.Keys {
+			sb.WriteString(fmt.Sprintf("    %s  %s\n", padRight(k.Key, keyWidth), strings.Join(k.Values, ", ")))
+		}
+		sb.WriteString("  units:\n")
+		unitWidth := maxWidth(units)
+		for _, u := range s.Units {
+			sb.WriteString(fmt.Sprintf("    %s  %d samples\n", padRight(u.Unit, unitWidth), u.Samples))
+		}
+	}
+	if _, err := io.WriteString(out, sb.String()); err != nil {
+		return errors.Wrap(err, "unable to write summary")
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package internal
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarizeBenchmarks(t *testing.T) {
+	summaries := SummarizeBenchmarks(mustParse(run1).Results)
+	require.Len(t, summaries, 2)
+	require.Equal(t, BenchmarkSummary{
+		Name:    "BenchmarkTdigest_Add",
+		Results: 4,
+		Keys: []KeySummary{
+			{Key: "goos", Values: []string{"linux"}},
+			{Key: "goarch", Values: []string{"amd64"}},
+			{Key: "pkg", Values: []string{"github.com/cep21/tdigestbench"}},
+			{Key: "source", Values: []string{"linear", "rand"}},
+			{Key: "digest", Values: []string{"caio", "segmentio"}},
+			{Key: ProcsKey, Values: []string{"8"}},
+		},
+		Units: []UnitSummary{
+			{Unit: "ns/op", Samples: 4},
+			{Unit: "B/op", Samples: 4},
+			{Unit: "allocs/op", Samples: 4},
+		},
+	}, summaries[1])
+}
+
+func TestWriteSummaries(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteSummaries(&buf, SummarizeBenchmarks(mustParse(run2).Results)))
+	require.Equal(t, `BenchmarkTest (3 results)
+  keys:
+    unused  unused
+    name    bob, john
+    type    digest, sign
+    procs   1
+  units:
+    ns/op  3 samples
+`, buf.String())
+}
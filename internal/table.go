@@ -0,0 +1,235 @@
+package internal
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// TablePlotter writes the values a plot would draw as a table instead of a picture
+type TablePlotter struct {
+	// Format is one of csv, tsv, json or markdown
+	Format string
+}
+
+// TableFormats are the formats TablePlotter can write
+var TableFormats = []string{"csv", "tsv", "json", "markdown"}
+
+type tableCell struct {
+	X       string   `json:"x"`
+	Value   *float64 `json:"value"`
+	Samples int      `json:"samples"`
+}
+
+type tableRow struct {
+	Group  string      `json:"group"`
+	Values []tableCell `json:"values"`
+}
+
+type tablePanel struct {
+	Unit   string     `json:"unit"`
+	Groups []tableRow `json:"groups"`
+}
+
+type tableJSON struct {
+	Title  string       `json:"title"`
+	X      string       `json:"x"`
+	Panels []tablePanel `json:"panels"`
+}
+
+type tableDelta struct {
+	Name      string  `json:"name"`
+	Baseline  float64 `json:"baseline"`
+	Candidate float64 `json:"candidate"`
+	Change    float64 `json:"change"`
+}
+
+type tableDeltaJSON struct {
+	Title  string       `json:"title"`
+	Unit   string       `json:"unit"`
+	Deltas []tableDelta `json:"deltas"`
+}
+
+// Render will write to out each panel as a table with a row for each line and a column for each x value.  Each cell
+// is the aggregated value and how many samples it is of.
+func (t *TablePlotter) Render(log Logger, out io.Writer, chart Chart) error {
+	if chart.Type == PlotTypeDelta {
+		return t.renderDelta(out, chart)
+	}
+	agg, x, panels, uniqueKeys := chart.Aggregation, chart.X, chart.Panels, chart.XValues
+	tables := make([]tablePanel, 0, len(panels))
+	for _, panel := range panels {
+		tp := tablePanel{Unit: panel.Y}
+		for _, line := range panel.Lines {
+			row := tableRow{Group: line.Name}
+			for i, vals := range line.Values {
+				cell := tableCell{X: uniqueKeys.Order[i], Samples: len(vals)}
+				if len(vals) != 0 {
+					v := agg(vals)
+					cell.Value = &v
+				}
+				row.Values = append(row.Values, cell)
+			}
+			tp.Groups = append(tp.Groups, row)
+		}
+		tables = append(tables, tp)
+	}
+	log.Log(2, "table: %v", tables)
+	switch t.Format {
+	case "json":
+		for _, tp := range tables {
+			for _, g := range tp.Groups {
+				for _, c := range g.Values {
+					if c.Value != nil {
+						*c.Value = jsonFinite(*c.Value)
+					}
+				}
+			}
+		}
+		return writeJSON(out, tableJSON{Title: chart.Title, X: x, Panels: tables})
+	case "markdown":
+		var sb strings.Builder
+		for i, tp := range tables {
+			if i != 0 {
+				sb.WriteString("\n")
+			}
+			header := []string{tp.Unit}
+			for _, xv := range uniqueKeys.Order {
+				header = append(header, termXLabel(x, xv))
+			}
+			rows := make([][]string, 0, len(tp.Groups))
+			for _, g := range tp.Groups {
+				row := []string{g.Group}
+				for _, c := range g.Values {
+					if c.Value == nil {
+						row = append(row, "-")
+						continue
+					}
+					row = append(row, fmt.Sprintf("%s (n=%d)", formatTermValue(*c.Value), c.Samples))
+				}
+				rows = append(rows, row)
+			}
+			writeMarkdownTable(&sb, header, rows)
+		}
+		return writeTableString(out, sb.String())
+	}
+	header := []string{"unit", "group"}
+	for _, xv := range uniqueKeys.Order {
+		header = append(header, termXLabel(x, xv), termXLabel(x, xv)+" samples")
+	}
+	var rows [][]string
+	for _, tp := range tables {
+		for _, g := range tp.Groups {
+			row := []string{tp.Unit, g.Group}
+			for _, c := range g.Values {
+				value := ""
+				if c.Value != nil {
+					value = strconv.FormatFloat(*c.Value, 'g', -1, 64)
+				}
+				row = append(row, value, strconv.Itoa(c.Samples))
+			}
+			rows = append(rows, row)
+		}
+	}
+	return t.writeCSV(out, header, rows)
+}
+
+// renderDelta will write to out a table of each delta's baseline, candidate and percent change
+func (t *TablePlotter) renderDelta(out io.Writer, chart Chart) error {
+	y := chart.Unit
+	tds := make([]tableDelta, 0, len(chart.Deltas))
+	for _, d := range chart.Deltas {
+		tds = append(tds, tableDelta{
+			Name:      d.Name,
+			Baseline:  d.Baseline,
+			Candidate: d.Candidate,
+			Change:    d.PercentChange(),
+		})
+	}
+	switch t.Format {
+	case "json":
+		for i := range tds {
+			tds[i].Change = jsonFinite(tds[i].Change)
+		}
+		return writeJSON(out, tableDeltaJSON{Title: chart.Title, Unit: y, Deltas: tds})
+	case "markdown":
+		rows := make([][]string, 0, len(tds))
+		for _, d := range tds {
+			rows = append(rows, []string{d.Name, formatTermValue(d.Baseline), formatTermValue(d.Candidate), fmt.Sprintf("%+.1f%%", d.Change)})
+		}
+		var sb strings.Builder
+		writeMarkdownTable(&sb, []string{"benchmark", "baseline " + y, "candidate " + y, "change"}, rows)
+		return writeTableString(out, sb.String())
+	}
+	rows := make([][]string, 0, len(tds))
+	for _, d := range tds {
+		rows = append(rows, []string{
+			d.Name,
+			strconv.FormatFloat(d.Baseline, 'g', -1, 64),
+			strconv.FormatFloat(d.Candidate, 'g', -1, 64),
+			strconv.FormatFloat(d.Change, 'g', -1, 64),
+		})
+	}
+	return t.writeCSV(out, []string{"benchmark", "baseline " + y, "candidate " + y, "change %"}, rows)
+}
+
+func (t *TablePlotter) writeCSV(out io.Writer, header []string, rows [][]string) error {
+	w := csv.NewWriter(out)
+	if t.Format == "tsv" {
+		w.Comma = '\t'
+	}
+	if err := w.Write(header); err != nil {
+		return errors.Wrap(err, "unable to write table header")
+	}
+	if err := w.WriteAll(rows); err != nil {
+		return errors.Wrap(err, "unable to write table")
+	}
+	return nil
+}
+
+func writeJSON(out io.Writer, v interface{}) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return errors.Wrap(err, "unable to write json")
+	}
+	return nil
+}
+
+func writeTableString(out io.Writer, s string) error {
+	if _, err := io.WriteString(out, s); err != nil {
+		return errors.Wrap(err, "unable to write table")
+	}
+	return nil
+}
+
+func writeMarkdownTable(sb *strings.Builder, header []string, rows [][]string) {
+	escape := func(s string) string {
+		return strings.Replace(s, "|", "\\|", -1)
+	}
+	writeRow := func(cells []string) {
+		sb.WriteString("|")
+		for _, c := range cells {
+			sb.WriteString(" " + escape(c) + " |")
+		}
+		sb.WriteString("\n")
+	}
+	writeRow(header)
+	sb.WriteString("|")
+	for i := range header {
+		if i == 0 {
+			sb.WriteString(" --- |")
+			continue
+		}
+		sb.WriteString(" ---: |")
+	}
+	sb.WriteString("\n")
+	for _, row := range rows {
+		writeRow(row)
+	}
+}
@@ -0,0 +1,75 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTablePlotter_Plot(t *testing.T) {
+	panels := []PlotPanel{{
+		Y: "ns/op",
+		Lines: []PlotLine{
+			{Name: "a|b", Values: [][]float64{{1, 3}, {}}},
+			{Name: "c", Values: [][]float64{{5}, {0.5}}},
+		},
+	}}
+	xValues := makeSet("1", "2")
+	plotEqual := func(format string, expected string) func(t *testing.T) {
+		return func(t *testing.T) {
+			tp := TablePlotter{Format: format}
+			var buf bytes.Buffer
+			require.NoError(t, tp.Render(Logger{}, &buf, Chart{Title: "title", X: "size", XValues: xValues, Panels: panels, Aggregation: meanAggregation}))
+			require.Equal(t, expected, buf.String())
+		}
+	}
+	t.Run("csv", plotEqual("csv", strings.Join([]string{
+		"unit,group,size=1,size=1 samples,size=2,size=2 samples",
+		"ns/op,a|b,2,2,,0",
+		"ns/op,c,5,1,0.5,1",
+		"",
+	}, "\n")))
+	t.Run("tsv", plotEqual("tsv", strings.Join([]string{
+		"unit\tgroup\tsize=1\tsize=1 samples\tsize=2\tsize=2 samples",
+		"ns/op\ta|b\t2\t2\t\t0",
+		"ns/op\tc\t5\t1\t0.5\t1",
+		"",
+	}, "\n")))
+	t.Run("markdown", plotEqual("markdown", strings.Join([]string{
+		"| ns/op | size=1 | size=2 |",
+		"| --- | ---: | ---: |",
+		"| a\\|b | 2 (n=2) | - |",
+		"| c | 5 (n=1) | 0.5 (n=1) |",
+		"",
+	}, "\n")))
+	t.Run("json", func(t *testing.T) {
+		tp := TablePlotter{Format: "json"}
+		var buf bytes.Buffer
+		require.NoError(t, tp.Render(Logger{}, &buf, Chart{Title: "title", X: "size", XValues: xValues, Panels: panels, Aggregation: meanAggregation}))
+		var got tableJSON
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+		require.Equal(t, "size", got.X)
+		require.Equal(t, "ns/op", got.Panels[0].Unit)
+		require.Equal(t, "a|b", got.Panels[0].Groups[0].Group)
+		require.Equal(t, 2.0, *got.Panels[0].Groups[0].Values[0].Value)
+		require.Equal(t, 2, got.Panels[0].Groups[0].Values[0].Samples)
+		require.Nil(t, got.Panels[0].Groups[0].Values[1].Value)
+	})
+}
+
+func TestTablePlotter_PlotDelta(t *testing.T) {
+	deltas := []Delta{
+		{Name: "BenchmarkA", Baseline: 10, Candidate: 20},
+	}
+	tp := TablePlotter{Format: "csv"}
+	var buf bytes.Buffer
+	require.NoError(t, tp.Render(Logger{}, &buf, Chart{Type: PlotTypeDelta, Unit: "ns/op", Deltas: deltas}))
+	require.Equal(t, "benchmark,baseline ns/op,candidate ns/op,change %\nBenchmarkA,10,20,100\n", buf.String())
+	tp.Format = "markdown"
+	buf.Reset()
+	require.NoError(t, tp.Render(Logger{}, &buf, Chart{Type: PlotTypeDelta, Unit: "ns/op", Deltas: deltas}))
+	require.Contains(t, buf.String(), "| BenchmarkA | 10 | 20 | +100.0% |")
+}
@@ -0,0 +1,397 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// TermPlotter draws plots as text for a terminal
+type TermPlotter struct {
+	// Width is how many columns to draw in.  Zero means the chart's width, then the width of the terminal being
+	// written to, then $COLUMNS, then 80.
+	Width int
+	// TTY draws with Unicode block characters and ANSI colors, which are also used when writing to a terminal.
+	// Without it, plots are plain ASCII.
+	TTY bool
+}
+
+// termColors are ANSI foreground colors, in about the order plotutil picks colors
+var termColors = []int{31, 32, 34, 33, 35, 36}
+
+// termMarkers tell lines apart in plain ASCII, where there are no colors
+var termMarkers = []rune{'*', '+', 'o', 'x', '#', '@', '%', '&'}
+
+// termLineHeight is how many rows a line plot is
+const termLineHeight = 12
+
+// Render will write to out every panel as text.  Bar and box plots are drawn as horizontal bars of each aggregated
+// value, and line plots as a grid of characters.
+func (t *TermPlotter) Render(log Logger, out io.Writer, chart Chart) error {
+	tp := *t
+	if tp.Width == 0 {
+		tp.Width = int(chart.Style.Width)
+	}
+	terminal := isTerminal(out)
+	if tp.Width == 0 && terminal {
+		tp.Width = terminalWidth(out.(*os.File))
+	}
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && tp.Width == 0 {
+		tp.Width = columns
+	}
+	tp.TTY = tp.TTY || terminal
+	if chart.Type == PlotTypeDelta {
+		return tp.renderDelta(out, chart)
+	}
+	return tp.render(log, out, chart)
+}
+
+func (t *TermPlotter) render(log Logger, out io.Writer, chart Chart) error {
+	pt, agg, scale, title, x, panels, uniqueKeys := chart.Type, chart.Aggregation, chart.Scale, chart.Title, chart.X, chart.ReadablePanels(), chart.XValues
+	if pt == PlotTypeBox {
+		log.Log(1, "terminal output draws box plots as bars")
+	}
+	if scale.LogY {
+		log.Log(1, "terminal output ignores logy")
+	}
+	var sb strings.Builder
+	if title != "" {
+		sb.WriteString(title + "\n")
+	}
+	for i, panel := range panels {
+		if i != 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(panel.Y + "\n")
+		if pt == PlotTypeLine {
+			pos, err := termXPositions(scale, uniqueKeys.Order)
+			if err != nil {
+				return errors.Wrap(err, "unable to place x values")
+			}
+			t.drawLines(&sb, agg, x, panel.Lines, uniqueKeys.Order, pos)
+		} else {
+			t.drawBars(&sb, agg, x, panel.Lines, uniqueKeys.Order)
+		}
+	}
+	if pt == PlotTypeLine || len(panels) > 1 {
+		t.drawLegend(&sb, panels[0].Lines)
+	}
+	if _, err := io.WriteString(out, sb.String()); err != nil {
+		return errors.Wrap(err, "unable to write plot to output")
+	}
+	return nil
+}
+
+// renderDelta will write to out a horizontal bar for each delta's percent change
+func (t *TermPlotter) renderDelta(out io.Writer, chart Chart) error {
+	title, y, deltas := chart.Title, chart.Unit, chart.Deltas
+	var sb strings.Builder
+	if title != "" {
+		sb.WriteString(title + "\n")
+	}
+	sb.WriteString("% change in " + y + "\n")
+	names := make([]string, 0, len(deltas))
+	values := make([]string, 0, len(deltas))
+	maxChange := 0.0
+	for _, d := range deltas {
+		names = append(names, d.Name)
+		values = append(values, fmt.Sprintf("%+.1f%%", d.PercentChange()))
+		if !math.IsInf(d.PercentChange(), 0) {
+			maxChange = math.Max(maxChange, math.Abs(d.PercentChange()))
+		}
+	}
+	nameWidth, valueWidth := maxWidth(names), maxWidth(values)
+	barWidth := t.barWidth(nameWidth, valueWidth)
+	for i, d := range deltas {
+		color := 32
+		if d.PercentChange() != 0 && (d.PercentChange() > 0) != HigherIsBetter(y) {
+			color = 31
+		}
+		change := math.Min(math.Abs(d.PercentChange()), maxChange)
+		sb.WriteString(padRight(names[i], nameWidth) + " " + t.colorize(t.bar(change, maxChange, barWidth), color) + " " + values[i] + "\n")
+	}
+	if _, err := io.WriteString(out, sb.String()); err != nil {
+		return errors.Wrap(err, "unable to write plot to output")
+	}
+	return nil
+}
+
+func (t *TermPlotter) width() int {
+	if t.Width <= 0 {
+		return 80
+	}
+	return t.Width
+}
+
+// barWidth is how many columns are left for bars after a label and value
+func (t *TermPlotter) barWidth(labelWidth int, valueWidth int) int {
+	w := t.width() - labelWidth - valueWidth - 4
+	if w < 10 {
+		return 10
+	}
+	return w
+}
+
+func (t *TermPlotter) drawBars(sb *strings.Builder, agg Aggregation, x string, lines []PlotLine, nominalX []string) {
+	// Without line names, each x value is a single bar
+	unnamed := len(lines) == 1 && lines[0].Name == ""
+	labels := make([]string, 0, len(lines))
+	for _, line := range lines {
+		labels = append(labels, line.Name)
+	}
+	for _, xv := range nominalX {
+		labels = append(labels, termXLabel(x, xv))
+	}
+	maxValue := 0.0
+	var values []string
+	for _, line := range lines {
+		for _, vals := range line.Values {
+			if v, ok := termValue(vals, agg); ok {
+				maxValue = math.Max(maxValue, math.Abs(v))
+				values = append(values, formatTermValue(v))
+			}
+		}
+	}
+	labelWidth, valueWidth := maxWidth(labels)+2, maxWidth(values)
+	barWidth := t.barWidth(labelWidth, valueWidth)
+	for i, xv := range nominalX {
+		if !unnamed {
+			sb.WriteString(termXLabel(x, xv) + "\n")
+		}
+		for j, line := range lines {
+			v, ok := termValue(line.Values[i], agg)
+			if !ok {
+				continue
+			}
+			label := "  " + line.Name
+			if unnamed {
+				label = termXLabel(x, xv)
+			}
+			sb.WriteString(padRight(label, labelWidth) + " " + t.colorize(t.bar(math.Abs(v), maxValue, barWidth), termColors[j%len(termColors)]) + " " + formatTermValue(v) + "\n")
+		}
+	}
+}
+
+// termValue is vals aggregated to the one value drawn.  It is false if there are no values, or they aggregate to a
+// value that is not finite and so cannot be drawn.
+func termValue(vals []float64, agg Aggregation) (float64, bool) {
+	if len(vals) == 0 {
+		return 0, false
+	}
+	v := agg(vals)
+	return v, !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+// bar is v as a bar of at most width columns.  In a TTY, partial columns are drawn with eighth blocks.
+func (t *TermPlotter) bar(v float64, maxValue float64, width int) string {
+	if maxValue <= 0 || v <= 0 {
+		return ""
+	}
+	cols := v / maxValue * float64(width)
+	if !t.TTY {
+		return strings.Repeat("#", int(math.Round(cols)))
+	}
+	full := int(cols)
+	ret := strings.Repeat("█", full)
+	if eighths := int(math.Round((cols - float64(full)) * 8)); eighths > 0 && eighths < 8 {
+		ret += string([]rune("▏▎▍▌▋▊▉")[eighths-1])
+	} else if eighths == 8 {
+		ret += "█"
+	}
+	return ret
+}
+
+type termCell struct {
+	r     rune
+	color int
+}
+
+func (t *TermPlotter) drawLines(sb *strings.Builder, agg Aggregation, x string, lines []PlotLine, nominalX []string, xPositions []float64) {
+	minY, maxY := math.Inf(1), math.Inf(-1)
+	for _, line := range lines {
+		for _, vals := range line.Values {
+			if v, ok := termValue(vals, agg); ok {
+				minY, maxY = math.Min(minY, v), math.Max(maxY, v)
+			}
+		}
+	}
+	if math.IsInf(minY, 1) {
+		sb.WriteString("(no values)\n")
+		return
+	}
+	if minY == maxY {
+		minY, maxY = minY-1, maxY+1
+	}
+	yLabels := []string{formatTermValue(maxY), formatTermValue((minY + maxY) / 2), formatTermValue(minY)}
+	yWidth := maxWidth(yLabels)
+	width := t.width() - yWidth - 2
+	if width < 10 {
+		width = 10
+	}
+	minX, maxX := xPositions[0], xPositions[len(xPositions)-1]
+	col := func(pos float64) int {
+		if maxX == minX {
+			return width / 2
+		}
+		return int(math.Round((pos - minX) / (maxX - minX) * float64(width-1)))
+	}
+	row := func(v float64) int {
+		return int(math.Round((maxY - v) / (maxY - minY) * float64(termLineHeight-1)))
+	}
+	grid := make([][]termCell, termLineHeight)
+	for i := range grid {
+		grid[i] = make([]termCell, width)
+	}
+	for i, line := range lines {
+		color := termColors[i%len(termColors)]
+		segment, point := '•', '●'
+		if !t.TTY {
+			segment = termMarkers[i%len(termMarkers)]
+			point = segment
+		}
+		prev, v0 := -1, 0.0
+		for j, vals := range line.Values {
+			v1, ok := termValue(vals, agg)
+			if !ok {
+				continue
+			}
+			if prev != -1 {
+				c0, c1 := col(xPositions[prev]), col(xPositions[j])
+				for c := c0 + 1; c < c1; c++ {
+					v := v0 + (v1-v0)*float64(c-c0)/float64(c1-c0)
+					grid[row(v)][c] = termCell{r: segment, color: color}
+				}
+			}
+			prev, v0 = j, v1
+		}
+		for j, vals := range line.Values {
+			if v, ok := termValue(vals, agg); ok {
+				grid[row(v)][col(xPositions[j])] = termCell{r: point, color: color}
+			}
+		}
+	}
+	for i, cells := range grid {
+		label := ""
+		switch i {
+		case 0:
+			label = yLabels[0]
+		case termLineHeight / 2:
+			label = yLabels[1]
+		case termLineHeight - 1:
+			label = yLabels[2]
+		}
+		var rowText strings.Builder
+		for _, c := range cells {
+			if c.r == 0 {
+				rowText.WriteString(" ")
+				continue
+			}
+			rowText.WriteString(t.colorize(string(c.r), c.color))
+		}
+		sb.WriteString(padLeft(label, yWidth) + " " + t.axis('│', '|') + strings.TrimRight(rowText.String(), " ") + "\n")
+	}
+	sb.WriteString(strings.Repeat(" ", yWidth+1) + t.axis('└', '+') + strings.Repeat(t.axis('─', '-'), width) + "\n")
+	// Write each x label under its value, skipping labels that would overlap the one before
+	labels := []rune(strings.Repeat(" ", width+maxWidth(nominalX)))
+	next := 0
+	for i, xv := range nominalX {
+		c := col(xPositions[i])
+		start := c - utf8.RuneCountInString(xv)/2
+		if start < 0 {
+			start = 0
+		}
+		if start < next {
+			continue
+		}
+		copy(labels[start:], []rune(xv))
+		next = start + utf8.RuneCountInString(xv) + 1
+	}
+	sb.WriteString(strings.Repeat(" ", yWidth+2) + strings.TrimRight(string(labels), " ") + "\n")
+	if x != "" {
+		// Center x under the plot, unless it is wider than the terminal
+		pad := yWidth + 2 + width/2 - utf8.RuneCountInString(x)/2
+		if pad < 0 {
+			pad = 0
+		}
+		sb.WriteString(strings.Repeat(" ", pad) + x + "\n")
+	}
+}
+
+func (t *TermPlotter) drawLegend(sb *strings.Builder, lines []PlotLine) {
+	for i, line := range lines {
+		if line.Name == "" {
+			continue
+		}
+		marker := "██"
+		if !t.TTY {
+			marker = string(termMarkers[i%len(termMarkers)])
+		}
+		sb.WriteString(t.colorize(marker, termColors[i%len(termColors)]) + " " + line.Name + "\n")
+	}
+}
+
+func (t *TermPlotter) axis(tty rune, ascii rune) string {
+	if t.TTY {
+		return string(tty)
+	}
+	return string(ascii)
+}
+
+func (t *TermPlotter) colorize(s string, color int) string {
+	if !t.TTY || s == "" {
+		return s
+	}
+	return fmt.Sprintf("\x1b[%dm%s\x1b[0m", color, s)
+}
+
+// termXPositions is the numeric position of each x value, or its index if x is nominal
+func termXPositions(scale AxisScale, nominalX []string) ([]float64, error) {
+	pm, err := makePlacement(AxisScale{NumericX: scale.NumericX, LogX: scale.LogX}, nil, nominalX)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]float64, 0, len(nominalX))
+	for i := range nominalX {
+		pos := pm.x(i)
+		if scale.LogX {
+			pos = math.Log10(pos)
+		}
+		ret = append(ret, pos)
+	}
+	return ret, nil
+}
+
+func termXLabel(x string, value string) string {
+	if x == "" {
+		return value
+	}
+	return x + "=" + value
+}
+
+func formatTermValue(v float64) string {
+	return fmt.Sprintf("%.4g", v)
+}
+
+func maxWidth(s []string) int {
+	ret := 0
+	for _, v := range s {
+		if w := utf8.RuneCountInString(v); w > ret {
+			ret = w
+		}
+	}
+	return ret
+}
+
+func padRight(s string, width int) string {
+	return s + strings.Repeat(" ", width-utf8.RuneCountInString(s))
+}
+
+func padLeft(s string, width int) string {
+	return strings.Repeat(" ", width-utf8.RuneCountInString(s)) + s
+}
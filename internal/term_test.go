@@ -0,0 +1,107 @@
+package internal
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTermPlotter_Plot(t *testing.T) {
+	panels := []PlotPanel{{
+		Y: "ns/op",
+		Lines: []PlotLine{
+			{Name: "a", Values: [][]float64{{2}, {4}}},
+			{Name: "b", Values: [][]float64{{1}, {}}},
+		},
+	}}
+	xValues := makeSet("1", "2")
+	t.Run("bars", func(t *testing.T) {
+		tp := TermPlotter{Width: 30}
+		var buf bytes.Buffer
+		require.NoError(t, tp.Render(Logger{}, &buf, Chart{Title: "title", X: "size", XValues: xValues, Panels: panels, Type: PlotTypeBar, Aggregation: meanAggregation}))
+		require.Equal(t, strings.Join([]string{
+			"title",
+			"ns/op",
+			"size=1",
+			"  a      ######### 2",
+			"  b      #### 1",
+			"size=2",
+			"  a      ################# 4",
+			"",
+		}, "\n"), buf.String())
+	})
+	t.Run("tty", func(t *testing.T) {
+		tp := TermPlotter{Width: 30, TTY: true}
+		var buf bytes.Buffer
+		require.NoError(t, tp.Render(Logger{}, &buf, Chart{X: "size", XValues: xValues, Panels: panels, Type: PlotTypeBar, Aggregation: meanAggregation}))
+		require.Contains(t, buf.String(), "\x1b[31m"+strings.Repeat("█", 17)+"\x1b[0m 4")
+		require.Contains(t, buf.String(), "\x1b[32m████▎\x1b[0m 1")
+	})
+	t.Run("lines", func(t *testing.T) {
+		tp := TermPlotter{Width: 30}
+		var buf bytes.Buffer
+		require.NoError(t, tp.Render(Logger{}, &buf, Chart{X: "size", XValues: xValues, Panels: panels, Type: PlotTypeLine, Aggregation: meanAggregation, Scale: AxisScale{NumericX: true}}))
+		lines := strings.Split(buf.String(), "\n")
+		require.Equal(t, "ns/op", lines[0])
+		require.Equal(t, "  4 |"+strings.Repeat(" ", 23)+"**", lines[1])
+		require.Equal(t, "  1 |+", lines[termLineHeight])
+		require.Equal(t, "* a", lines[len(lines)-3])
+		require.Equal(t, "+ b", lines[len(lines)-2])
+	})
+	t.Run("narrow", func(t *testing.T) {
+		tp := TermPlotter{Width: 20}
+		x := "a_very_long_benchmark_key_name_"
+		var buf bytes.Buffer
+		require.NoError(t, tp.Render(Logger{}, &buf, Chart{X: x, XValues: xValues, Panels: panels, Type: PlotTypeLine, Aggregation: meanAggregation}))
+		require.Contains(t, buf.String(), "\n"+x+"\n")
+	})
+	t.Run("empty", func(t *testing.T) {
+		tp := TermPlotter{}
+		var buf bytes.Buffer
+		require.NoError(t, tp.Render(Logger{}, &buf, Chart{X: "size", Panels: []PlotPanel{{Y: "ns/op"}}, Type: PlotTypeLine, Aggregation: meanAggregation}))
+		require.Contains(t, buf.String(), "(no values)")
+	})
+	t.Run("notfinite", func(t *testing.T) {
+		notFinite := []PlotPanel{{
+			Y: "B/alloc",
+			Lines: []PlotLine{
+				{Name: "a", Values: [][]float64{{math.NaN()}, {4}}},
+				{Name: "b", Values: [][]float64{{1}, {math.Inf(1)}}},
+			},
+		}}
+		tp := TermPlotter{Width: 30}
+		var buf bytes.Buffer
+		require.NoError(t, tp.Render(Logger{}, &buf, Chart{X: "size", XValues: xValues, Panels: notFinite, Type: PlotTypeBar, Aggregation: meanAggregation}))
+		require.Equal(t, strings.Join([]string{
+			"B/alloc",
+			"size=1",
+			"  b      #### 1",
+			"size=2",
+			"  a      ################# 4",
+			"",
+		}, "\n"), buf.String())
+		buf.Reset()
+		require.NoError(t, tp.Render(Logger{}, &buf, Chart{X: "size", XValues: xValues, Panels: notFinite, Type: PlotTypeLine, Aggregation: meanAggregation, Scale: AxisScale{NumericX: true}}))
+		require.NotContains(t, buf.String(), "NaN")
+		require.NotContains(t, buf.String(), "Inf")
+	})
+}
+
+func TestTermPlotter_PlotDelta(t *testing.T) {
+	tp := TermPlotter{Width: 30}
+	var buf bytes.Buffer
+	deltas := []Delta{
+		{Name: "BenchmarkA", Baseline: 10, Candidate: 20},
+		{Name: "BenchmarkB", Baseline: 10, Candidate: 5},
+	}
+	require.NoError(t, tp.Render(Logger{}, &buf, Chart{Type: PlotTypeDelta, Unit: "ns/op", Deltas: deltas}))
+	require.Equal(t, strings.Join([]string{
+		"% change in ns/op",
+		"BenchmarkA ########## +100.0%",
+		"BenchmarkB ##### -50.0%",
+		"",
+	}, "\n"), buf.String())
+}
@@ -0,0 +1,11 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+// +build !aix,!darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package internal
+
+import "os"
+
+// terminalWidth is how many columns the terminal f writes to is, or 0 if it is unknown
+func terminalWidth(f *os.File) int {
+	return 0
+}
@@ -0,0 +1,134 @@
+package internal
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// magnitude is a prefix of a unit and how many base units it is
+type magnitude struct {
+	prefix string
+	factor float64
+}
+
+var (
+	timeMagnitudes = []magnitude{
+		{prefix: "ns", factor: 1},
+		{prefix: "µs", factor: 1e3},
+		{prefix: "ms", factor: 1e6},
+		{prefix: "s", factor: 1e9},
+	}
+	binaryByteMagnitudes = []magnitude{
+		{prefix: "B", factor: 1},
+		{prefix: "KiB", factor: 1 << 10},
+		{prefix: "MiB", factor: 1 << 20},
+		{prefix: "GiB", factor: 1 << 30},
+		{prefix: "TiB", factor: 1 << 40},
+	}
+	// Go's benchmark MB/s is 1e6 bytes a second
+	decimalByteMagnitudes = []magnitude{
+		{prefix: "B", factor: 1},
+		{prefix: "kB", factor: 1e3},
+		{prefix: "MB", factor: 1e6},
+		{prefix: "GB", factor: 1e9},
+		{prefix: "TB", factor: 1e12},
+	}
+	siMagnitudes = []magnitude{
+		{prefix: "", factor: 1},
+		{prefix: "k", factor: 1e3},
+		{prefix: "M", factor: 1e6},
+		{prefix: "G", factor: 1e9},
+		{prefix: "T", factor: 1e12},
+	}
+)
+
+// ScaleUnit returns a unit that makes maxValue of unit a human friendly number, and what to divide values of unit
+// by to get values of the new unit.  Times go from ns up to s, B/op uses binary prefixes, MB/s uses decimal ones and
+// other units that start with a letter get SI prefixes.  Unknown units, like percentages, are not scaled.
+func ScaleUnit(unit string, maxValue float64) (string, float64) {
+	head, rest := unit, ""
+	if idx := strings.Index(unit, "/"); idx != -1 {
+		head, rest = unit[:idx], unit[idx:]
+	}
+	family, base := unitFamily(head)
+	if family == nil {
+		first := []rune(unit)
+		if len(first) == 0 || !unicode.IsLetter(first[0]) || strings.ContainsRune(unit, ' ') {
+			return unit, 1
+		}
+		family, base, rest = siMagnitudes, 0, unit
+	}
+	maxBase := math.Abs(maxValue) * family[base].factor
+	// Values under 1 keep their unit, since mallocs/op reads worse than allocs/op
+	best := base
+	for i, m := range family {
+		if maxBase >= m.factor {
+			best = i
+		}
+	}
+	return family[best].prefix + rest, family[best].factor / family[base].factor
+}
+
+func unitFamily(head string) ([]magnitude, int) {
+	if head == "ns" {
+		return timeMagnitudes, 0
+	}
+	if head == "B" {
+		return binaryByteMagnitudes, 0
+	}
+	for i, m := range decimalByteMagnitudes {
+		if i != 0 && m.prefix == head {
+			return decimalByteMagnitudes, i
+		}
+	}
+	return nil, 0
+}
+
+// ReadablePanels returns the panels of c, scaled by ScalePanel if c.Scale.ReadableY is set.  Renderers that draw a
+// picture use them in place of c.Panels.
+func (c Chart) ReadablePanels() []PlotPanel {
+	if !c.Scale.ReadableY {
+		return c.Panels
+	}
+	ret := make([]PlotPanel, 0, len(c.Panels))
+	for _, p := range c.Panels {
+		ret = append(ret, ScalePanel(p))
+	}
+	return ret
+}
+
+// ScalePanel returns panel with every value, and its unit, scaled by ScaleUnit
+func ScalePanel(panel PlotPanel) PlotPanel {
+	maxValue := 0.0
+	for _, line := range panel.Lines {
+		for _, vals := range line.Values {
+			for _, v := range vals {
+				maxValue = math.Max(maxValue, math.Abs(v))
+			}
+		}
+	}
+	unit, factor := ScaleUnit(panel.Y, maxValue)
+	if factor == 1 {
+		return PlotPanel{Y: unit, Lines: panel.Lines}
+	}
+	ret := PlotPanel{
+		Y:     unit,
+		Lines: make([]PlotLine, 0, len(panel.Lines)),
+	}
+	for _, line := range panel.Lines {
+		scaled := PlotLine{
+			Name:   line.Name,
+			Values: make([][]float64, 0, len(line.Values)),
+		}
+		for _, vals := range line.Values {
+			scaledVals := make([]float64, 0, len(vals))
+			for _, v := range vals {
+				scaledVals = append(scaledVals, v/factor)
+			}
+			scaled.Values = append(scaled.Values, scaledVals)
+		}
+		ret.Lines = append(ret.Lines, scaled)
+	}
+	return ret
+}
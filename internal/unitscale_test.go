@@ -0,0 +1,65 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScaleUnit(t *testing.T) {
+	scaleEqual := func(unit string, maxValue float64, expectedUnit string, expectedFactor float64) func(t *testing.T) {
+		return func(t *testing.T) {
+			gotUnit, gotFactor := ScaleUnit(unit, maxValue)
+			require.Equal(t, expectedUnit, gotUnit)
+			require.InDelta(t, expectedFactor, gotFactor, 1e-9)
+		}
+	}
+	t.Run("ns", scaleEqual("ns/op", 999, "ns/op", 1))
+	t.Run("µs", scaleEqual("ns/op", 1000, "µs/op", 1e3))
+	t.Run("ms", scaleEqual("ns/op", 13879794, "ms/op", 1e6))
+	t.Run("s", scaleEqual("ns/op", 2e12, "s/op", 1e9))
+	t.Run("small", scaleEqual("ns/op", 0.0649, "ns/op", 1))
+	t.Run("B", scaleEqual("B/op", 1023, "B/op", 1))
+	t.Run("KiB", scaleEqual("B/op", 41508, "KiB/op", 1024))
+	t.Run("MiB", scaleEqual("B/op", 3<<20, "MiB/op", 1<<20))
+	t.Run("GB/s", scaleEqual("MB/s", 2500, "GB/s", 1e3))
+	t.Run("kB/s", scaleEqual("MB/s", 0.5, "kB/s", 1e-3))
+	t.Run("MB/s", scaleEqual("MB/s", 84.35, "MB/s", 1))
+	t.Run("allocs", scaleEqual("allocs/op", 150, "allocs/op", 1))
+	t.Run("kallocs", scaleEqual("allocs/op", 15000, "kallocs/op", 1e3))
+	t.Run("negative", scaleEqual("ns/op", -5000, "µs/op", 1e3))
+	t.Run("percent", scaleEqual("%correct", 1e6, "%correct", 1))
+	t.Run("expression", scaleEqual("1e9 / ns/op", 1e6, "1e9 / ns/op", 1))
+	t.Run("empty", scaleEqual("", 1e6, "", 1))
+}
+
+func TestScalePanel(t *testing.T) {
+	panel := PlotPanel{
+		Y: "ns/op",
+		Lines: []PlotLine{
+			{Name: "a", Values: [][]float64{{1000, 3000}, {}}},
+			{Name: "b", Values: [][]float64{{500}, {2000}}},
+		},
+	}
+	require.Equal(t, PlotPanel{
+		Y: "µs/op",
+		Lines: []PlotLine{
+			{Name: "a", Values: [][]float64{{1, 3}, {}}},
+			{Name: "b", Values: [][]float64{{0.5}, {2}}},
+		},
+	}, ScalePanel(panel))
+	unscaled := PlotPanel{Y: "%correct", Lines: panel.Lines}
+	require.Equal(t, unscaled, ScalePanel(unscaled))
+}
+
+func TestChart_ReadablePanels(t *testing.T) {
+	panels := []PlotPanel{{
+		Y:     "ns/op",
+		Lines: []PlotLine{{Name: "a", Values: [][]float64{{2000}}}},
+	}}
+	require.Equal(t, panels, Chart{Panels: panels}.ReadablePanels())
+	require.Equal(t, []PlotPanel{{
+		Y:     "µs/op",
+		Lines: []PlotLine{{Name: "a", Values: [][]float64{{2}}}},
+	}}, Chart{Panels: panels, Scale: AxisScale{ReadableY: true}}.ReadablePanels())
+}
@@ -0,0 +1,212 @@
+package internal
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// VegaLitePlotter writes a Vega-Lite spec, with the plotted values inline, that other tools can restyle and draw
+type VegaLitePlotter struct {
+}
+
+const vegaLiteSchema = "https://vega.github.io/schema/vega-lite/v5.json"
+
+// vlObject is a JSON object of a Vega-Lite spec
+type vlObject map[string]interface{}
+
+// Render will write to out a Vega-Lite spec of every panel, stacked vertically
+func (v *VegaLitePlotter) Render(log Logger, out io.Writer, chart Chart) error {
+	if chart.Type == PlotTypeDelta {
+		return v.renderDelta(out, chart)
+	}
+	pt, agg, eb, scale, uniqueKeys := chart.Type, chart.Aggregation, chart.ErrorBars, chart.Scale, chart.XValues
+	pm, err := makePlacement(AxisScale{NumericX: scale.NumericX, LogX: scale.LogX}, nil, uniqueKeys.Order)
+	if err != nil {
+		return errors.Wrap(err, "unable to place x values")
+	}
+	numericX := scale.NumericX || scale.LogX
+	var values []vlObject
+	specs := make([]vlObject, 0, len(chart.Panels))
+	for _, panel := range chart.Panels {
+		groups := make([]string, 0, len(panel.Lines))
+		for _, line := range panel.Lines {
+			groups = append(groups, line.Name)
+			for i, vals := range line.Values {
+				if len(vals) == 0 {
+					continue
+				}
+				datum := func() vlObject {
+					ret := vlObject{
+						"unit":  panel.Y,
+						"group": line.Name,
+						"x":     uniqueKeys.Order[i],
+					}
+					if numericX {
+						ret["x"] = pm.x(i)
+					}
+					return ret
+				}
+				// Box plots are computed by Vega-Lite from every sample
+				if pt == PlotTypeBox {
+					for _, val := range vals {
+						d := datum()
+						d["y"] = jsonFinite(val)
+						values = append(values, d)
+					}
+					continue
+				}
+				d := datum()
+				d["y"] = jsonFinite(agg(vals))
+				d["samples"] = len(vals)
+				if eb != nil {
+					below, above := eb(agg(vals), vals)
+					d["low"] = jsonFinite(agg(vals) - below)
+					d["high"] = jsonFinite(agg(vals) + above)
+				}
+				values = append(values, d)
+			}
+		}
+		specs = append(specs, v.panelSpec(pt, eb != nil, scale, chart.X, panel.Y, groups, uniqueKeys.Order))
+	}
+	spec := vlObject{
+		"$schema": vegaLiteSchema,
+		"data":    vlObject{"values": values},
+	}
+	if chart.Title != "" {
+		spec["title"] = chart.Title
+	}
+	if len(specs) == 1 {
+		for k, val := range specs[0] {
+			spec[k] = val
+		}
+	} else {
+		spec["vconcat"] = specs
+	}
+	log.Log(2, "vega-lite spec: %v", spec)
+	return writeJSON(out, spec)
+}
+
+func (v *VegaLitePlotter) panelSpec(pt PlotType, errorBars bool, scale AxisScale, x string, y string, groups []string, nominalX []string) vlObject {
+	xEnc := vlObject{
+		"field": "x",
+		"type":  "nominal",
+		"title": x,
+		"sort":  nominalX,
+	}
+	if scale.NumericX || scale.LogX {
+		xEnc = vlObject{
+			"field": "x",
+			"type":  "quantitative",
+			"title": x,
+		}
+		if scale.LogX {
+			xEnc["scale"] = vlObject{"type": "log"}
+		}
+	}
+	yEnc := vlObject{
+		"field": "y",
+		"type":  "quantitative",
+		"title": y,
+	}
+	if scale.LogY {
+		yEnc["scale"] = vlObject{"type": "log"}
+	}
+	encoding := vlObject{
+		"x": xEnc,
+		"y": yEnc,
+		"color": vlObject{
+			"field": "group",
+			"type":  "nominal",
+			"sort":  groups,
+			"title": "",
+		},
+	}
+	var mark interface{}
+	switch pt {
+	case PlotTypeLine:
+		mark = vlObject{"type": "line", "point": true}
+	case PlotTypeBox:
+		// Box plots have their own tooltip of the box's summary
+		mark = vlObject{"type": "boxplot"}
+	default:
+		mark = vlObject{"type": "bar"}
+		// Bars of each group sit side by side within an x value
+		encoding["xOffset"] = vlObject{"field": "group", "sort": groups}
+	}
+	if pt != PlotTypeBox {
+		encoding["tooltip"] = []vlObject{
+			{"field": "group", "type": "nominal"},
+			{"field": "x", "type": xEnc["type"], "title": x},
+			{"field": "y", "type": "quantitative", "title": y},
+			{"field": "samples", "type": "quantitative"},
+		}
+	}
+	layer := vlObject{
+		"mark":     mark,
+		"encoding": encoding,
+	}
+	ret := vlObject{
+		"transform": []vlObject{{"filter": vlObject{"field": "unit", "equal": y}}},
+	}
+	if !errorBars || pt == PlotTypeBox {
+		for k, val := range layer {
+			ret[k] = val
+		}
+		return ret
+	}
+	errorEncoding := vlObject{
+		"x":     xEnc,
+		"y":     vlObject{"field": "low", "type": "quantitative", "title": y},
+		"y2":    vlObject{"field": "high"},
+		"color": vlObject{"field": "group", "type": "nominal", "sort": groups, "legend": nil},
+	}
+	if pt != PlotTypeLine {
+		errorEncoding["xOffset"] = vlObject{"field": "group", "sort": groups}
+	}
+	ret["layer"] = []vlObject{layer, {"mark": "errorbar", "encoding": errorEncoding}}
+	return ret
+}
+
+// renderDelta will write to out a Vega-Lite spec with a horizontal bar for each delta's percent change
+func (v *VegaLitePlotter) renderDelta(out io.Writer, chart Chart) error {
+	y := chart.Unit
+	values := make([]vlObject, 0, len(chart.Deltas))
+	names := make([]string, 0, len(chart.Deltas))
+	for _, d := range chart.Deltas {
+		change := d.PercentChange()
+		names = append(names, d.Name)
+		values = append(values, vlObject{
+			"name":      d.Name,
+			"baseline":  d.Baseline,
+			"candidate": d.Candidate,
+			"change":    jsonFinite(change),
+			"improved":  change == 0 || (change > 0) == HigherIsBetter(y),
+		})
+	}
+	spec := vlObject{
+		"$schema": vegaLiteSchema,
+		"data":    vlObject{"values": values},
+		"mark":    "bar",
+		"encoding": vlObject{
+			"y": vlObject{"field": "name", "type": "nominal", "sort": names, "title": ""},
+			"x": vlObject{"field": "change", "type": "quantitative", "title": "% change in " + y},
+			"color": vlObject{
+				"field":  "improved",
+				"type":   "nominal",
+				"scale":  vlObject{"domain": []bool{true, false}, "range": []string{"green", "red"}},
+				"legend": nil,
+			},
+			"tooltip": []vlObject{
+				{"field": "name", "type": "nominal"},
+				{"field": "baseline", "type": "quantitative"},
+				{"field": "candidate", "type": "quantitative"},
+				{"field": "change", "type": "quantitative"},
+			},
+		},
+	}
+	if chart.Title != "" {
+		spec["title"] = chart.Title
+	}
+	return writeJSON(out, spec)
+}
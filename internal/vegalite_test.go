@@ -0,0 +1,70 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVegaLitePlotter_Plot(t *testing.T) {
+	panels := []PlotPanel{
+		{Y: "ns/op", Lines: []PlotLine{{Name: "a", Values: [][]float64{{1, 3}, {}}}}},
+		{Y: "B/op", Lines: []PlotLine{{Name: "a", Values: [][]float64{{8}, {16}}}}},
+	}
+	plot := func(pt PlotType, eb ErrorBars, scale AxisScale, panels []PlotPanel) map[string]interface{} {
+		var buf bytes.Buffer
+		v := VegaLitePlotter{}
+		require.NoError(t, v.Render(Logger{}, &buf, Chart{Title: "title", X: "size", XValues: makeSet("10", "20"), Panels: panels, Type: pt, Aggregation: meanAggregation, ErrorBars: eb, Scale: scale}))
+		var ret map[string]interface{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &ret))
+		return ret
+	}
+	t.Run("bar", func(t *testing.T) {
+		spec := plot(PlotTypeBar, nil, AxisScale{}, panels[:1])
+		require.Equal(t, vegaLiteSchema, spec["$schema"])
+		require.Equal(t, "title", spec["title"])
+		require.Equal(t, map[string]interface{}{"type": "bar"}, spec["mark"])
+		values := spec["data"].(map[string]interface{})["values"].([]interface{})
+		require.Equal(t, []interface{}{
+			map[string]interface{}{"group": "a", "samples": 2.0, "unit": "ns/op", "x": "10", "y": 2.0},
+		}, values)
+		x := spec["encoding"].(map[string]interface{})["x"].(map[string]interface{})
+		require.Equal(t, "nominal", x["type"])
+		require.Equal(t, []interface{}{"10", "20"}, x["sort"])
+	})
+	t.Run("line", func(t *testing.T) {
+		spec := plot(PlotTypeLine, minmaxErrorBars, AxisScale{LogX: true, LogY: true}, panels)
+		vconcat := spec["vconcat"].([]interface{})
+		require.Len(t, vconcat, 2)
+		layers := vconcat[0].(map[string]interface{})["layer"].([]interface{})
+		require.Len(t, layers, 2)
+		require.Equal(t, "errorbar", layers[1].(map[string]interface{})["mark"])
+		x := layers[0].(map[string]interface{})["encoding"].(map[string]interface{})["x"].(map[string]interface{})
+		require.Equal(t, "quantitative", x["type"])
+		require.Equal(t, map[string]interface{}{"type": "log"}, x["scale"])
+		first := spec["data"].(map[string]interface{})["values"].([]interface{})[0].(map[string]interface{})
+		require.Equal(t, 10.0, first["x"])
+		require.Equal(t, 1.0, first["low"])
+		require.Equal(t, 3.0, first["high"])
+	})
+	t.Run("box", func(t *testing.T) {
+		spec := plot(PlotTypeBox, nil, AxisScale{}, panels[:1])
+		require.Equal(t, map[string]interface{}{"type": "boxplot"}, spec["mark"])
+		// Every sample is its own value
+		require.Len(t, spec["data"].(map[string]interface{})["values"], 2)
+	})
+}
+
+func TestVegaLitePlotter_PlotDelta(t *testing.T) {
+	var buf bytes.Buffer
+	v := VegaLitePlotter{}
+	require.NoError(t, v.Render(Logger{}, &buf, Chart{Type: PlotTypeDelta, Unit: "MB/s", Deltas: []Delta{{Name: "BenchmarkA", Baseline: 10, Candidate: 20}}}))
+	var spec map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &spec))
+	require.Equal(t, "bar", spec["mark"])
+	values := spec["data"].(map[string]interface{})["values"].([]interface{})
+	require.Equal(t, true, values[0].(map[string]interface{})["improved"])
+	require.Equal(t, 100.0, values[0].(map[string]interface{})["change"])
+}
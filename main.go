@@ -1,80 +1,78 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
 	"strings"
 
+	"github.com/cep21/benchdraw/draw"
 	"github.com/cep21/benchdraw/internal"
 
 	"github.com/pkg/errors"
 )
 
 type Application struct {
-	benchreader internal.BenchmarkReader
-	filter      internal.Filter
-	grouper     internal.Grouper
-	plotter     internal.Plotter
-	fs          flag.FlagSet
-	config      config
-	parameters  []string
-	log         internal.Logger
-	osExit      func(int)
-	stdIn       io.Reader
-	stdOut      io.Writer
+	fs         flag.FlagSet
+	config     config
+	parameters []string
+	log        internal.Logger
+	osExit     func(int)
+	stdIn      io.Reader
+	stdOut     io.Writer
+	inputs     inputCache
 }
 
 type config struct {
-	filter string
-	title  string
-	group  string
-	plot   string
-	x      string
-	y      string
-	input  string
-	output string
-	format string
-}
-
-func filterEmpty(s []string) []string {
-	ret := make([]string, 0, len(s))
-	for _, i := range s {
-		if len(i) > 0 {
-			ret = append(ret, i)
-		}
-	}
-	return ret
+	filter    string
+	title     string
+	group     string
+	plot      string
+	agg       string
+	errbar    string
+	normalize string
+	xscale    string
+	xsort     string
+	groupsort string
+	logx      bool
+	logy      bool
+	x         string
+	y         string
+	yexpr     string
+	input     string
+	baseline  string
+	output    string
+	format    string
+	width     float64
+	height    float64
+	dpi       int
+	// configFile lists many charts to draw instead of the one chart of the other flags
+	configFile string
+	// auto draws the charts picked from the data into outdir
+	auto   bool
+	outdir string
+	// list prints what benchmarks, keys and units there are to draw instead of drawing
+	list bool
+	// check compares input to baseline and fails if any benchmark regressed more than threshold
+	check     bool
+	threshold string
 }
 
-func (c config) parse(stdin io.Reader, stdout io.Writer) (*parsedConfig, error) {
-	ret := parsedConfig{
-		title:       c.title,
-		filters:     internal.ToFilterPairs(c.filter),
-		group:       filterEmpty(strings.Split(c.group, "/")),
-		imageFormat: c.format,
-		y:           c.y,
-		x:           c.x,
-	}
-	if ret.title == "" {
-		ret.title = c.filter
-	}
-	pt, err := internal.ToPlotType(c.plot)
+func (c config) parse(inputs *inputCache, stdout io.Writer, log internal.Logger) (*parsedConfig, error) {
+	opts, input, err := c.options(inputs, log)
 	if err != nil {
-		return nil, errors.Wrapf(err, "unable to understand plot type %s", c.plot)
+		return nil, err
 	}
-	ret.plot = pt
-	if c.input == "-" || c.input == "" {
-		ret.input = stdin
-	} else {
-		f, err := os.Open(c.input)
-		if err != nil {
-			return nil, errors.Wrapf(err, "unable to open file for reading %s", c.input)
-		}
-		ret.input = f
-		ret.onClose = append(ret.onClose, f.Close)
+	ret := parsedConfig{
+		opts:  opts,
+		input: input,
 	}
 	if c.output == "-" || c.output == "" {
 		ret.output = stdout
@@ -89,22 +87,89 @@ func (c config) parse(stdin io.Reader, stdout io.Writer) (*parsedConfig, error)
 	return &ret, nil
 }
 
+// options are the draw.Options of c, and its input
+func (c config) options(inputs *inputCache, log internal.Logger) (draw.Options, io.Reader, error) {
+	format, err := draw.ToFormat(c.format, c.output)
+	if err != nil {
+		return draw.Options{}, nil, errors.Wrapf(err, "unable to understand format %s", c.format)
+	}
+	opts := draw.Options{
+		Filter:    c.filter,
+		Title:     c.title,
+		Group:     strings.Split(c.group, "/"),
+		Plot:      c.plot,
+		Agg:       c.agg,
+		ErrorBars: c.errbar,
+		Normalize: c.normalize,
+		XScale:    c.xscale,
+		XSort:     c.xsort,
+		GroupSort: c.groupsort,
+		LogX:      c.logx,
+		LogY:      c.logy,
+		X:         c.x,
+		Y:         strings.Split(c.y, ","),
+		YExpr:     strings.Split(c.yexpr, ","),
+		Format:    format,
+		Width:     c.width,
+		Height:    c.height,
+		DPI:       c.dpi,
+		Threshold: c.threshold,
+		Logger:    log.Logger,
+		Verbosity: log.Verbosity,
+	}
+	input, err := inputs.open(c.input)
+	if err != nil {
+		return draw.Options{}, nil, errors.Wrap(err, "unable to read input")
+	}
+	if c.baseline != "" {
+		if opts.Baseline, err = inputs.open(c.baseline); err != nil {
+			return draw.Options{}, nil, errors.Wrap(err, "unable to read baseline")
+		}
+	}
+	return opts, input, nil
+}
+
 type parsedConfig struct {
-	title   string
-	filters []internal.FilterPair
-	group   []string
-	plot    internal.PlotType
-	x       string
-	y       string
-	input   io.Reader
-	output  io.Writer
+	opts   draw.Options
+	input  io.Reader
+	output io.Writer
 
-	onClose     []func() error
-	imageFormat string
+	onClose []func() error
 }
 
 func (p *parsedConfig) String() string {
-	return fmt.Sprintf("[title=%s x=%s y=%s]", p.title, p.x, p.y)
+	return fmt.Sprintf("[title=%s x=%s y=%s]", p.opts.Title, p.opts.X, strings.Join(p.opts.Y, ","))
+}
+
+// inputCache remembers every input read, so drawing many charts reads each input once
+type inputCache struct {
+	stdin io.Reader
+	read  map[string][]byte
+}
+
+// open returns the contents of the file name, or of stdin if name is - or empty
+func (c *inputCache) open(name string) (io.Reader, error) {
+	if name == "" {
+		name = "-"
+	}
+	if b, exists := c.read[name]; exists {
+		return bytes.NewReader(b), nil
+	}
+	var b []byte
+	var err error
+	if name == "-" {
+		b, err = ioutil.ReadAll(c.stdin)
+	} else {
+		b, err = ioutil.ReadFile(name)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read %s", name)
+	}
+	if c.read == nil {
+		c.read = make(map[string][]byte)
+	}
+	c.read[name] = b
+	return bytes.NewReader(b), nil
 }
 
 func (p *parsedConfig) Close() error {
@@ -140,7 +205,24 @@ func (a *Application) run() error {
 		return errors.Wrap(err, "unable to setup flags")
 	}
 	a.log.Log(1, "application startup")
-	pcfg, err := a.config.parse(a.stdIn, a.stdOut)
+	a.inputs.stdin = a.stdIn
+	if a.config.configFile != "" {
+		return a.runConfigFile(a.config.configFile)
+	}
+	if a.config.auto {
+		return a.runAuto(a.config)
+	}
+	if a.config.list {
+		return a.runList(a.config)
+	}
+	if a.config.check {
+		return a.runCheck(a.config)
+	}
+	return a.draw(a.config)
+}
+
+func (a *Application) draw(c config) error {
+	pcfg, err := c.parse(&a.inputs, a.stdOut, a.log)
 	a.log.Log(1, "finished config parsing")
 	if err != nil {
 		return errors.Wrap(err, "unable to parse config")
@@ -151,63 +233,224 @@ func (a *Application) run() error {
 			a.log.Log(1, "unable to shutdown config: %s", err)
 		}
 	}()
-	run, err := a.benchreader.ReadBenchmarks(pcfg.input)
-	a.log.Log(3, "benchmarks: %s", run)
+	chart, err := draw.Draw(context.Background(), pcfg.input, pcfg.opts)
 	if err != nil {
-		return errors.Wrap(err, "unable to read benchmark data")
-	}
-	filteredResults := a.filter.FilterBenchmarks(run.Results, pcfg.filters, pcfg.y)
-	a.log.Log(3, "filtered Results: %s", filteredResults)
-	uniqueKeys := filteredResults.UniqueValuesForKey(pcfg.x)
-	a.log.Log(3, "uniqueKeys: %s", uniqueKeys)
-	var groupSet internal.OrderedStringSet
-	for _, g := range pcfg.group {
-		groupSet.Add(g)
-	}
-	// When grouping by nothing, default to grouping by everything but the x axis.
-	if len(groupSet.Items) == 0 {
-		for _, r := range filteredResults {
-			for _, k := range r.AllKeyValuePairs().Order {
-				if k != pcfg.x {
-					groupSet.Add(k)
-				}
+		return errors.Wrap(err, "unable to draw chart")
+	}
+	return chart.Render(pcfg.output)
+}
+
+// runList writes a summary of each benchmark that matches c.filter
+func (a *Application) runList(c config) error {
+	opts, input, err := c.options(&a.inputs, a.log)
+	if err != nil {
+		return errors.Wrap(err, "unable to parse config")
+	}
+	listing, err := draw.List(context.Background(), input, opts)
+	if err != nil {
+		return errors.Wrap(err, "unable to list benchmarks")
+	}
+	out := a.stdOut
+	if c.output != "" && c.output != "-" {
+		f, err := os.Create(c.output)
+		if err != nil {
+			return errors.Wrapf(err, "unable to open file for writing %s", c.output)
+		}
+		defer func() {
+			if err := f.Close(); err != nil {
+				a.log.Log(1, "unable to close %s: %s", c.output, err)
 			}
+		}()
+		out = f
+	}
+	return listing.Write(out)
+}
+
+// runCheck prints how each benchmark of c.input changed from c.baseline, and fails if any regressed more than its
+// threshold.  With an --output, it also draws a delta plot of the first unit.
+func (a *Application) runCheck(c config) error {
+	if c.baseline == "" {
+		return errors.New("check needs a --baseline to compare against")
+	}
+	opts, input, err := c.options(&a.inputs, a.log)
+	if err != nil {
+		return errors.Wrap(err, "unable to parse config")
+	}
+	report, err := draw.Check(context.Background(), input, opts)
+	if err != nil {
+		return errors.Wrap(err, "unable to check benchmarks")
+	}
+	if err := report.Write(a.stdOut); err != nil {
+		return err
+	}
+	if c.output != "" && c.output != "-" {
+		cc := c
+		cc.plot = "delta"
+		cc.y = report.Results[0].Unit
+		if err := a.draw(cc); err != nil {
+			return errors.Wrap(err, "unable to draw chart")
+		}
+	}
+	if regressed := report.Regressed(); regressed != 0 {
+		return errors.Errorf("%d of %d benchmarks regressed more than their threshold", regressed, len(report.Results))
+	}
+	return nil
+}
+
+// runAuto draws every chart draw.Auto picks into c.outdir
+func (a *Application) runAuto(c config) error {
+	if c.outdir == "" {
+		return errors.New("--auto needs an --outdir to draw charts into")
+	}
+	if c.output != "" && c.output != "-" {
+		return errors.New("--auto draws charts into --outdir instead of --output")
+	}
+	opts, input, err := c.options(&a.inputs, a.log)
+	if err != nil {
+		return errors.Wrap(err, "unable to parse config")
+	}
+	charts, err := draw.Auto(context.Background(), input, opts)
+	if err != nil {
+		return errors.Wrap(err, "unable to pick charts")
+	}
+	if len(charts) == 0 {
+		return errors.New("no benchmarks to draw")
+	}
+	if err := os.MkdirAll(c.outdir, 0755); err != nil {
+		return errors.Wrapf(err, "unable to make directory %s", c.outdir)
+	}
+	for _, chart := range charts {
+		cc := c
+		cc.filter = chart.Options.Filter
+		cc.title = chart.Options.Title
+		cc.x = chart.Options.X
+		cc.group = strings.Join(chart.Options.Group, "/")
+		cc.y = strings.Join(chart.Options.Y, ",")
+		cc.plot = chart.Options.Plot
+		cc.output = filepath.Join(c.outdir, chart.Name+"."+opts.Format)
+		a.log.Log(1, "drawing %s", cc.output)
+		if err := a.draw(cc); err != nil {
+			return errors.Wrapf(err, "unable to draw %s", cc.output)
 		}
 	}
-	// Each group is a line in our graph
-	a.log.Log(3, "groupSet: %v", groupSet)
-	grouped := a.grouper.GroupBenchmarks(filteredResults, groupSet)
-	a.log.Log(3, "grouped: %v", grouped)
-	grouped.Normalize()
-	a.log.Log(3, "normalize: %v", grouped)
-
-	plotLines := make([]internal.PlotLine, 0, len(grouped))
-	for _, g := range grouped {
-		// For this line in our graph, compute the X Values
-		allVals := g.Results.ValuesByX(pcfg.x, pcfg.y, uniqueKeys)
-		pl := internal.PlotLine{
-			Name:   internal.NominalLineName(g.Values, grouped.AllSingleKey()),
-			Values: allVals,
+	return nil
+}
+
+// chartsFile is a config file of many charts.  Each chart is an object of flag names to values, which override the
+// flags given on the command line for that chart.
+type chartsFile struct {
+	Charts []map[string]interface{} `json:"charts"`
+}
+
+func (a *Application) runConfigFile(name string) error {
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		return errors.Wrapf(err, "unable to read config file %s", name)
+	}
+	var cf chartsFile
+	if err := json.Unmarshal(b, &cf); err != nil {
+		return errors.Wrapf(err, "unable to parse config file %s", name)
+	}
+	base, baseVerbosity := a.config, a.log.Verbosity
+	defer func() {
+		a.config, a.log.Verbosity = base, baseVerbosity
+	}()
+	// outputs is the chart that writes to each output file
+	outputs := make(map[string]int, len(cf.Charts))
+	for i, chart := range cf.Charts {
+		// Setting the flags of a chart changes a.config and a.log, which start each chart as the command line left them
+		a.config, a.log.Verbosity = base, baseVerbosity
+		for k, v := range chart {
+			if chartOnlyDraws[k] {
+				return errors.Errorf("chart %d of %s cannot set %s: charts of a config file are only drawn", i, name, k)
+			}
+			if err := a.fs.Set(k, configValue(v)); err != nil {
+				return errors.Wrapf(err, "unable to set %s of chart %d of %s", k, i, name)
+			}
 		}
-		a.log.Log(3, "nominal=%v plot=%v", pl.Name, pl)
-		plotLines = append(plotLines, pl)
-		a.log.Log(3, "plot line: %v", pl)
+		if len(cf.Charts) > 1 {
+			// Charts share the command line's output, so each needs its own
+			if _, exists := chart["output"]; !exists || a.config.output == "" || a.config.output == "-" {
+				return errors.Errorf("chart %d of %s needs an output file", i, name)
+			}
+			if j, exists := outputs[a.config.output]; exists {
+				return errors.Errorf("chart %d of %s writes to %s, the same as chart %d", i, name, a.config.output, j)
+			}
+			outputs[a.config.output] = i
+		}
+		a.log.Log(1, "drawing chart %d to %s", i, a.config.output)
+		if err := a.draw(a.config); err != nil {
+			return errors.Wrapf(err, "unable to draw chart %d (%s) of %s", i, a.config.output, name)
+		}
+	}
+	return nil
+}
+
+// chartOnlyDraws are the flags a chart of a config file cannot set, since they do something other than draw a chart
+var chartOnlyDraws = map[string]bool{
+	"config":    true,
+	"auto":      true,
+	"outdir":    true,
+	"list":      true,
+	"check":     true,
+	"threshold": true,
+}
+
+// configValue is a JSON value of a config file as a flag value.  A list is a comma separated value.
+func configValue(v interface{}) string {
+	switch tv := v.(type) {
+	case string:
+		return tv
+	case []interface{}:
+		vals := make([]string, 0, len(tv))
+		for _, i := range tv {
+			vals = append(vals, configValue(i))
+		}
+		return strings.Join(vals, ",")
 	}
-	return a.plotter.Plot(a.log, pcfg.output, pcfg.imageFormat, pcfg.plot, pcfg.title, pcfg.x, pcfg.y, plotLines, uniqueKeys)
+	return fmt.Sprint(v)
 }
 
 func (a *Application) setupFlags() error {
-	a.fs.StringVar(&a.config.plot, "plot", "bar", "Which picture type to plot.  Valid Values [bar,box]")
+	a.fs.StringVar(&a.config.plot, "plot", "bar", "Which picture type to plot.  Valid Values [bar,line,box,delta]")
+	a.fs.StringVar(&a.config.agg, "agg", "mean", "How to combine multiple values for the same x.  Valid Values [mean,median,min,max,sum,geomean,count,pN] where pN is the Nth percentile")
+	a.fs.StringVar(&a.config.errbar, "errorbars", "none", "Draw error bars from the values for each x.  Valid Values [none,stddev,stderr,ci95,minmax]")
+	a.fs.StringVar(&a.config.normalize, "normalize", "", "Draw each group relative to the group with this key=value.  The key must be a group")
+	a.fs.StringVar(&a.config.xscale, "xscale", "auto", "How to place x values.  Valid Values [auto,nominal,numeric].  auto is numeric for line plots of numbers")
+	a.fs.StringVar(&a.config.xsort, "xsort", "input", "How to order x values.  Valid Values [input,alpha,natural,numeric,value]")
+	a.fs.StringVar(&a.config.groupsort, "groupsort", "input", "How to order groups in the legend.  Valid Values [input,alpha,natural,numeric,value]")
+	a.fs.BoolVar(&a.config.logx, "logx", false, "Draw the X axis on a log scale.  X values must be positive numbers")
+	a.fs.BoolVar(&a.config.logy, "logy", false, "Draw the Y axis on a log scale.  Y values must be positive")
 	a.fs.StringVar(&a.config.filter, "filter", "", "Filter which benchmarks to graph.  See README for filter syntax")
 	a.fs.StringVar(&a.config.title, "title", "", "A title for your graph.  If empty, will use filter")
 	a.fs.StringVar(&a.config.group, "group", "", "Pick benchmarks tags to group together")
 	a.fs.StringVar(&a.config.x, "x", "", "Pick unit for the X axis")
-	a.fs.StringVar(&a.config.y, "y", "ns/op", "Pick unit for the Y axis")
+	a.fs.StringVar(&a.config.y, "y", "", "Pick unit for the Y axis.  A comma separated list draws one plot per unit.  If empty, uses y-expr or ns/op")
+	a.fs.StringVar(&a.config.yexpr, "y-expr", "", "Comma separated units computed from other units, like '1e9 / ns/op' or 'ops/s=1e9 / ns/op'.  See README for syntax")
 	a.fs.StringVar(&a.config.input, "input", "-", "Input file to read from.  - means stdin")
+	a.fs.StringVar(&a.config.baseline, "baseline", "", "Baseline file to compare input against.  Required by delta plots")
 	a.fs.StringVar(&a.config.output, "output", "-", "Output file to write to.  - means stdout")
-	a.fs.StringVar(&a.config.format, "format", "svg", "Which image format to render.  Must be supported by gonum/plot.  You probably want the default.")
+	a.fs.StringVar(&a.config.format, "format", "", "Which image format to render.  Valid Values ["+strings.Join(draw.Formats(), ",")+"].  If empty, uses the output file extension or svg")
+	a.fs.Float64Var(&a.config.width, "width", 0, "Image width in pixels for png, jpg and tif images, and in points otherwise.  If 0, picks a width from the number of values drawn, or keeps the default aspect ratio with --height")
+	a.fs.Float64Var(&a.config.height, "height", 0, "Image height in pixels for png, jpg and tif images, and in points otherwise.  If 0, keeps the default aspect ratio")
+	a.fs.IntVar(&a.config.dpi, "dpi", 0, "Resolution of png, jpg and tif images.  If 0, uses 96")
+	a.fs.StringVar(&a.config.configFile, "config", "", "JSON file of many charts to draw, reading each input once.  See README for the format")
+	a.fs.BoolVar(&a.config.auto, "auto", false, "Draw a chart of each benchmark and unit into --outdir, picking x and group from the keys that vary")
+	a.fs.StringVar(&a.config.outdir, "outdir", "", "Directory --auto draws charts into")
+	a.fs.BoolVar(&a.config.list, "list", false, "Print each benchmark's keys, values of each key and units instead of drawing.  Same as benchdraw list")
+	a.fs.BoolVar(&a.config.check, "check", false, "Compare input to --baseline, print how each benchmark changed and fail if any regressed more than --threshold.  Same as benchdraw check")
+	a.fs.StringVar(&a.config.threshold, "threshold", "", "Percent each benchmark can regress by before --check fails.  A comma separated list of a default and name=percent for benchmarks starting with name, like 5,BenchmarkDecode=10.  If empty, uses 5")
 	a.fs.IntVar(&a.log.Verbosity, "v", 0, "Higher the Value, the more verbose the output.  Max Value is 4")
-	if err := a.fs.Parse(a.parameters); err != nil {
+	params := a.parameters
+	// benchdraw list and benchdraw check are the same as --list and --check
+	if len(params) > 0 && params[0] == "list" {
+		a.config.list = true
+		params = params[1:]
+	} else if len(params) > 0 && params[0] == "check" {
+		a.config.check = true
+		params = params[1:]
+	}
+	if err := a.fs.Parse(params); err != nil {
 		return errors.Wrap(err, "unable to parse cli parameters")
 	}
 	return nil
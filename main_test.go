@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -18,6 +19,19 @@ func mustRead(t *testing.T, file string) string {
 	return string(b)
 }
 
+// newTestApplication returns an Application that reads input as stdin and writes to the returned buffer as stdout
+func newTestApplication(t *testing.T, input string, params ...string) (*Application, *bytes.Buffer) {
+	out := &bytes.Buffer{}
+	return &Application{
+		parameters: params,
+		log: internal.Logger{
+			Logger: log.New(os.Stderr, "benchdraw", log.LstdFlags),
+		},
+		stdIn:  strings.NewReader(mustRead(t, input)),
+		stdOut: out,
+	}, out
+}
+
 func TestTestData(t *testing.T) {
 	testExample := func(cmd string, inputFile string, expectedFile string) func(t *testing.T) {
 		return func(t *testing.T) {
@@ -53,5 +67,152 @@ func TestTestData(t *testing.T) {
 	t.Run("out8", testExample(`--filter=BenchmarkDecode/size=1e6 --x=level --group=text --y=allocs/op`, "./testdata/decodeexample.txt", "./examples/out8.svg"))
 	t.Run("out10", testExample(`--filter=BenchmarkDecode/size=1e6/text=twain --x=level --plot=line --y=allocs/op`, "./testdata/decodeexample.txt", "./examples/out10.svg"))
 	t.Run("out11", testExample(`--filter=BenchmarkDecode/text=twain --x=level --plot=line --y=allocs/op`, "./testdata/decodeexample.txt", "./examples/out11.svg"))
+	t.Run("box", testExample(`--filter=BenchmarkCorrectness/size=1000000 --x=quant --y=%correct --group=digest --plot=box`, "./testdata/benchresult.txt", "./examples/box.svg"))
+	t.Run("errorbars", testExample(`--filter=BenchmarkCorrectness/size=1000000 --x=quant --y=%correct --group=digest --errorbars=stddev`, "./testdata/benchresult.txt", "./examples/errorbars.svg"))
+	t.Run("delta", testExample(`--filter=BenchmarkTdigest_Add --plot=delta --baseline=./testdata/benchresult.txt`, "./testdata/simpleres.txt", "./examples/delta.svg"))
+	t.Run("normalized", testExample(`--filter=BenchmarkTdigest_Add --x=source --group=digest --normalize=digest=caio`, "./testdata/benchresult.txt", "./examples/normalized.svg"))
+	t.Run("log_scale", testExample(`--filter=BenchmarkDecode/level=best --x=size --plot=line --logx --logy`, "./testdata/decodeexample.txt", "./examples/log_scale.svg"))
+	t.Run("multi_y", testExample(`--filter=BenchmarkDecode/level=best --x=size --plot=line --y=ns/op,B/op,allocs/op`, "./testdata/decodeexample.txt", "./examples/multi_y.svg"))
+	t.Run("y_expr", testExample(`--filter=BenchmarkDecode/level=best --x=size --plot=line --y-expr=B/alloc=(B/op)/(allocs/op)`, "./testdata/decodeexample.txt", "./examples/y_expr.svg"))
+	t.Run("html", testExample(`--filter=BenchmarkCorrectness/size=1000000 --x=quant --y=%correct --group=digest --errorbars=stddev --format=html`, "./testdata/benchresult.txt", "./examples/errorbars.html"))
+	t.Run("sorted", testExample(`--filter=BenchmarkTdigest_Add --x=source --group=digest --xsort=value --groupsort=alpha`, "./testdata/simpleres.txt", "./examples/sorted.svg"))
 	t.Run("comits	", testExample(`--filter=BenchmarkDecode --x=commit --plot=line`, "./testdata/encodeovertime.txt", "./examples/comits.svg"))
 }
+
+func TestConfigFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "benchdraw")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	var logs bytes.Buffer
+	runConfig := func(charts string, params ...string) error {
+		configFile := filepath.Join(dir, "charts.json")
+		require.NoError(t, ioutil.WriteFile(configFile, []byte(charts), 0644))
+		logs.Reset()
+		instance, _ := newTestApplication(t, "./testdata/simpleres.txt", append([]string{"--config=" + configFile, "--filter=BenchmarkTdigest_Add"}, params...)...)
+		instance.log.Logger = log.New(&logs, "benchdraw", log.LstdFlags)
+		return instance.run()
+	}
+	t.Run("charts", func(t *testing.T) {
+		// Both charts read stdin, and inherit --filter from the command line
+		require.NoError(t, runConfig(`{"charts": [
+			{"x": "source", "output": "`+filepath.Join(dir, "simple.svg")+`"},
+			{"x": "source", "group": "digest", "xsort": "value", "groupsort": "alpha", "output": "`+filepath.Join(dir, "sorted.svg")+`"},
+			{"filter": "BenchmarkDecode/level=best", "input": "./testdata/decodeexample.txt", "x": "size", "y": ["ns/op", "B/op", "allocs/op"], "plot": "line", "output": "`+filepath.Join(dir, "multi_y.svg")+`"}
+		]}`))
+		require.Equal(t, mustRead(t, "./examples/piped_output.svg"), mustRead(t, filepath.Join(dir, "simple.svg")))
+		require.Equal(t, mustRead(t, "./examples/sorted.svg"), mustRead(t, filepath.Join(dir, "sorted.svg")))
+		require.Equal(t, mustRead(t, "./examples/multi_y.svg"), mustRead(t, filepath.Join(dir, "multi_y.svg")))
+	})
+	t.Run("nooutput", func(t *testing.T) {
+		require.Error(t, runConfig(`{"charts": [{"x": "source"}, {"x": "digest"}]}`))
+		// Charts cannot all overwrite the command line's output
+		require.Error(t, runConfig(`{"charts": [{"x": "source"}, {"x": "digest"}]}`, "--output="+filepath.Join(dir, "shared.svg")))
+		require.Error(t, runConfig(`{"charts": [{"x": "source", "output": "`+filepath.Join(dir, "a.svg")+`"}, {"x": "digest", "output": "`+filepath.Join(dir, "a.svg")+`"}]}`))
+	})
+	t.Run("verbosity", func(t *testing.T) {
+		// A chart's v is only for that chart
+		require.NoError(t, runConfig(`{"charts": [
+			{"x": "source", "v": 1, "output": "`+filepath.Join(dir, "v1.svg")+`"},
+			{"x": "digest", "output": "`+filepath.Join(dir, "v0.svg")+`"}
+		]}`))
+		require.Contains(t, logs.String(), "finished config parsing")
+		require.Equal(t, 1, strings.Count(logs.String(), "finished config parsing"))
+	})
+	t.Run("notdrawing", func(t *testing.T) {
+		for _, k := range []string{"config", "auto", "outdir", "list", "check", "threshold"} {
+			require.Error(t, runConfig(`{"charts": [{"x": "source", "`+k+`": "true"}]}`), k)
+		}
+	})
+	t.Run("unknownflag", func(t *testing.T) {
+		require.Error(t, runConfig(`{"charts": [{"colour": "red"}]}`))
+	})
+	t.Run("badjson", func(t *testing.T) {
+		require.Error(t, runConfig(`{"charts": [`))
+	})
+}
+
+func TestAuto(t *testing.T) {
+	dir, err := ioutil.TempDir("", "benchdraw")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	runAuto := func(params ...string) error {
+		instance, _ := newTestApplication(t, "./testdata/simpleres.txt", append([]string{"--auto"}, params...)...)
+		return instance.run()
+	}
+	require.NoError(t, runAuto("--outdir="+dir))
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	names := make([]string, 0, len(files))
+	for _, f := range files {
+		names = append(names, f.Name())
+	}
+	require.Equal(t, []string{"BenchmarkTdigest_Add_B_op.svg", "BenchmarkTdigest_Add_allocs_op.svg", "BenchmarkTdigest_Add_ns_op.svg"}, names)
+	// source has the most values, and digest is the only other key that varies
+	require.Equal(t, mustRead(t, "./examples/set_filename.svg"), mustRead(t, filepath.Join(dir, "BenchmarkTdigest_Add_ns_op.svg")))
+
+	require.Error(t, runAuto())
+	require.Error(t, runAuto("--outdir="+dir, "--output=chart.svg"))
+	require.Error(t, runAuto("--outdir="+dir, "--filter=BenchmarkMissing"))
+}
+
+func TestList(t *testing.T) {
+	list := func(params ...string) string {
+		instance, out := newTestApplication(t, "./testdata/benchresult.txt", params...)
+		require.NoError(t, instance.run())
+		return out.String()
+	}
+	out := list("list", "--filter=BenchmarkTdigest_Add/digest=caio")
+	require.Equal(t, out, list("--list", "--filter=BenchmarkTdigest_Add/digest=caio"))
+	require.Equal(t, `BenchmarkTdigest_Add (5 results)
+  keys:
+    goos    linux
+    goarch  amd64
+    pkg     github.com/cep21/tdigestbench
+    source  linear, rand, alternating, normal, tailspike
+    digest  caio
+    procs   8
+  units:
+    ns/op      5 samples
+    B/op       5 samples
+    allocs/op  5 samples
+`, out)
+	require.Contains(t, list("list", "--y-expr=ops/s=1e9 / ns/op"), "ops/s")
+}
+
+func TestCheck(t *testing.T) {
+	dir, err := ioutil.TempDir("", "benchdraw")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	check := func(params ...string) (string, error) {
+		instance, out := newTestApplication(t, "./testdata/simpleres.txt", params...)
+		err := instance.run()
+		return out.String(), err
+	}
+	out, err := check("check", "--baseline=./testdata/benchresult.txt", "--filter=BenchmarkTdigest_Add")
+	require.EqualError(t, err, "3 of 10 benchmarks regressed more than their threshold")
+	require.Contains(t, out, "REGRESSED  BenchmarkTdigest_Add/source=normal/digest=segmentio")
+	require.Contains(t, out, "3 of 10 benchmarks regressed\n")
+
+	out, err = check("--check", "--baseline=./testdata/benchresult.txt", "--filter=BenchmarkTdigest_Add", "--threshold=11")
+	require.NoError(t, err)
+	require.Contains(t, out, "0 of 10 benchmarks regressed\n")
+
+	_, err = check("check", "--baseline=./testdata/benchresult.txt", "--filter=BenchmarkTdigest_Add", "--threshold=5,BenchmarkTdigest_Add/source=normal=20", "--y=B/op,ns/op")
+	require.EqualError(t, err, "2 of 20 benchmarks regressed more than their threshold")
+
+	out, err = check("check", "--baseline=./testdata/benchresult.txt", "--filter=BenchmarkTdigest_Add", "--threshold=11", "--output="+filepath.Join(dir, "check.svg"))
+	require.NoError(t, err)
+	require.Contains(t, out, "0 of 10 benchmarks regressed\n")
+	require.FileExists(t, filepath.Join(dir, "check.svg"))
+
+	_, err = check("check")
+	require.Error(t, err)
+	_, err = check("check", "--baseline=./testdata/benchresult.txt", "--threshold=abc")
+	require.Error(t, err)
+}